@@ -8,123 +8,302 @@ import (
 )
 
 var (
+	// Deprecated: results can be added to a group while it is waiting,
+	// so this is no longer returned
 	ErrGroupAlreadyWaiting = errors.New("resultgroup is already waiting")
 )
 
 type Result[T any] struct {
-	// Channel to eventually receive the output on
-	outCh <-chan T
-	// Store the result from the output channel
-	out T
+	// The context passed to Eventually
+	ctx context.Context
+	// Cancels the context the function was called with
+	cancel context.CancelFunc
 
-	// Channel to eventually receive the error on
-	errCh <-chan error
-	// Store the error from the error channel
-	err error
+	// Closed once the function has returned
+	done chan struct{}
 
-	// Whether the function has returned yet
-	done bool
+	// The output and error of the function, only read them after
+	// done has been closed
+	out T
+	err error
 }
 
 func (r *Result[T]) Err() error {
-	if !r.done {
-		<-r.Done()
-	}
+	<-r.done
 	return r.err
 }
 
 func (r *Result[T]) Out() T {
-	if !r.done {
-		<-r.Done()
-	}
+	<-r.done
 	return r.out
 }
 
+// Returns a channel that is closed once the function has returned. It
+// can be called any number of times from any goroutine.
 func (r *Result[T]) Done() <-chan struct{} {
-	fin := make(chan struct{}, 1)
-
-	if r.done {
-		// If its done, send an empty struct so the call doesn't
-		// block
-		fin <- struct{}{}
-	} else {
-		// Wait for the results then send the finish signal down
-		// the channel
-		go func() {
-			r.out = <-r.outCh
-			r.err = <-r.errCh
-			r.done = true
-			fin <- struct{}{}
-		}()
-	}
+	return r.done
+}
 
-	return fin
+// Cancels the context the function was called with. It doesn't wait
+// for the function to return.
+func (r *Result[T]) Cancel() {
+	r.cancel()
 }
 
 func Eventually[T any](ctx context.Context, f Effector[T]) *Result[T] {
-	resCh := make(chan T, 1)
-	errCh := make(chan error, 1)
+	fctx, cancel := context.WithCancel(ctx)
+	res := &Result[T]{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
 
 	go func() {
-		res, err := f(ctx)
-		resCh <- res
-		errCh <- err
+		defer close(res.done)
+		defer cancel()
+		res.out, res.err = f(fctx)
 	}()
 
-	return &Result[T]{
-		outCh: resCh,
-		errCh: errCh,
-		done:  false,
-	}
+	return res
 }
 
+// Waits for a group of results to resolve, the zero value is ready to
+// use. It is safe for concurrent use, but must not be copied once it
+// has been used.
 type ResultGroup struct {
-	// Whether everything has resolved
-	done bool
-	// Whether the group has started work yet
-	working bool
+	// Cancel the rest of the results as soon as one of them fails
+	CancelOnError bool
 
-	results []resultItem
-
-	wg *sync.WaitGroup
+	mu sync.Mutex
+	// Every result that has been added
+	results []Awaitable
+	// The results that haven't been claimed by a wait yet
+	pending []Awaitable
+	// Closed when a result is added, so running waits can claim it
+	added chan struct{}
 }
 
-type resultItem interface {
+// Anything that can be added to a ResultGroup, such as a *Result. If
+// it also has an Err method, its error is collected by the group, and
+// if it has a Cancel method, it is cancelled by CancelOnError.
+type Awaitable interface {
 	Done() <-chan struct{}
 }
 
-// Add an item to the result group
-// This will return an error if Wait has already
-// been called. When Wait has finished, you can add
-// more results to resolve.
-func (r *ResultGroup) Add(res resultItem) error {
-	if r.working {
-		return ErrGroupAlreadyWaiting
+type failable interface {
+	Err() error
+}
+
+type cancellable interface {
+	Cancel()
+}
+
+// The error of a resolved item, or nil if it doesn't have one
+func awaitableErr(res Awaitable) error {
+	if f, ok := res.(failable); ok {
+		return f.Err()
 	}
+	return nil
+}
+
+// Add an item to the result group. It can be called from many
+// goroutines, including while the group is waiting, in which case the
+// running wait waits for the item too. It always returns nil.
+func (r *ResultGroup) Add(res Awaitable) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.results = append(r.results, res)
+	r.pending = append(r.pending, res)
+	if r.added != nil {
+		close(r.added)
+		r.added = nil
+	}
 	return nil
 }
 
 // Wait blocks until every result has resolved
 func (r *ResultGroup) Wait() {
-	r.working = true
-	defer func() { r.working = false }()
-	if r.done {
-		return
+	r.WaitContext(context.Background())
+}
+
+// WaitContext blocks until every result has resolved, or the context
+// is cancelled. It returns the context error if it was cancelled,
+// otherwise the errors from the results joined together.
+func (r *ResultGroup) WaitContext(ctx context.Context) error {
+	for range stream[Awaitable](ctx, r, r.CancelOnError) {
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	return r.Err()
+}
 
-	if r.wg == nil {
-		r.wg = &sync.WaitGroup{}
+// Completions returns a channel that receives each result as it
+// resolves, in the order they resolve. Results that are added while it
+// is running are sent too, and each result is only sent once, so
+// calling it again only sends the results added since. The channel is
+// closed once every result has been sent, or when the context is
+// cancelled. If you stop reading before then, cancel the context so
+// the results that weren't sent are sent by the next wait instead.
+func (r *ResultGroup) Completions(ctx context.Context) <-chan Awaitable {
+	return stream[Awaitable](ctx, r, r.CancelOnError)
+}
+
+// Claims the pending results of the group and sends each one as it
+// resolves, until none are left
+func stream[T Awaitable](ctx context.Context, r *ResultGroup, cancelOnError bool) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		// Stops the goroutines watching the results once we return
+		stop := make(chan struct{})
+		defer close(stop)
+
+		claimed := []Awaitable{}
+		sent := []bool{}
+		unsent := 0
+		ready := []int{}
+		resolved := make(chan int)
+		failed := false
+
+		for {
+			r.mu.Lock()
+			fresh := r.pending
+			r.pending = nil
+			if unsent+len(fresh) == 0 {
+				r.mu.Unlock()
+				return
+			}
+			if r.added == nil {
+				r.added = make(chan struct{})
+			}
+			added := r.added
+			r.mu.Unlock()
+
+			for _, res := range fresh {
+				i := len(claimed)
+				claimed = append(claimed, res)
+				sent = append(sent, false)
+				unsent++
+				if failed {
+					cancelAwaitable(res)
+				}
+				go func() {
+					select {
+					case <-stop:
+						return
+					case <-res.Done():
+					}
+					select {
+					case <-stop:
+					case resolved <- i:
+					}
+				}()
+			}
+
+			var out chan<- T
+			var next T
+			if len(ready) > 0 {
+				out = ch
+				next = claimed[ready[0]].(T)
+			}
+
+			select {
+			case <-ctx.Done():
+				// Give back the results that weren't sent, so the next
+				// wait sends them instead
+				back := []Awaitable{}
+				for i, res := range claimed {
+					if !sent[i] {
+						back = append(back, res)
+					}
+				}
+				r.mu.Lock()
+				r.pending = append(back, r.pending...)
+				r.mu.Unlock()
+				return
+			case <-added:
+			case i := <-resolved:
+				ready = append(ready, i)
+				if cancelOnError && !failed && awaitableErr(claimed[i]) != nil {
+					failed = true
+					for _, res := range claimed {
+						cancelAwaitable(res)
+					}
+				}
+			case out <- next:
+				sent[ready[0]] = true
+				ready = ready[1:]
+				unsent--
+			}
+		}
+	}()
+	return ch
+}
+
+func cancelAwaitable(res Awaitable) {
+	if c, ok := res.(cancellable); ok {
+		c.Cancel()
 	}
+}
+
+// Err returns the errors from the results that have resolved so far,
+// joined together
+func (r *ResultGroup) Err() error {
+	r.mu.Lock()
+	results := r.results
+	r.mu.Unlock()
 
-	for _, res := range r.results {
-		r.wg.Add(1)
-		go func(res resultItem) {
-			defer r.wg.Done()
-			<-res.Done()
-		}(res)
+	errs := []error{}
+	for _, res := range results {
+		select {
+		case <-res.Done():
+			if err := awaitableErr(res); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+		}
 	}
+	return errors.Join(errs...)
+}
+
+// Does the same as ResultGroup for results of a single type, so the
+// results from Completions don't need to be type asserted. The zero
+// value is ready to use.
+type TypedResultGroup[T any] struct {
+	// Cancel the rest of the results as soon as one of them fails
+	CancelOnError bool
+
+	group ResultGroup
+}
+
+// Add a result to the group, it always returns nil
+func (g *TypedResultGroup[T]) Add(res *Result[T]) error {
+	return g.group.Add(res)
+}
+
+// Wait blocks until every result has resolved
+func (g *TypedResultGroup[T]) Wait() {
+	g.WaitContext(context.Background())
+}
+
+// Does the same as ResultGroup.WaitContext
+func (g *TypedResultGroup[T]) WaitContext(ctx context.Context) error {
+	for range g.Completions(ctx) {
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return g.Err()
+}
+
+// Does the same as ResultGroup.Completions
+func (g *TypedResultGroup[T]) Completions(ctx context.Context) <-chan *Result[T] {
+	return stream[*Result[T]](ctx, &g.group, g.CancelOnError)
+}
 
-	r.wg.Wait()
-	r.done = true
+// Err returns the errors from the results that have resolved so far,
+// joined together
+func (g *TypedResultGroup[T]) Err() error {
+	return g.group.Err()
 }
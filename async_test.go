@@ -3,6 +3,7 @@ package flow_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -104,20 +105,273 @@ func TestAResultGroupWaitsForAllResultsToResolve(t *testing.T) {
 	assert.Greater(t, time.Since(start), time.Millisecond)
 }
 
-func TestItErrorsWhenAddingAResultWhileWeAreWaiting(t *testing.T) {
+func TestItWaitsForResultsAddedWhileWeAreWaiting(t *testing.T) {
 	group := flow.ResultGroup{}
+	release := make(chan struct{})
 
-	instant := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+	first := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		<-release
 		return 1, nil
 	})
-	slow := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+	later := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		<-release
 		time.Sleep(time.Millisecond * 5)
 		return 5, nil
 	})
 
-	group.Add(slow)
-	go group.Wait()
+	group.Add(first)
+	waited := make(chan struct{})
+	go func() {
+		group.Wait()
+		close(waited)
+	}()
 	// Wait for the goroutine to start up
 	time.Sleep(time.Millisecond)
-	assert.ErrorIs(t, group.Add(instant), flow.ErrGroupAlreadyWaiting)
+	assert.Nil(t, group.Add(later))
+
+	close(release)
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("wait never returned")
+	}
+	assert.Equal(t, 5, later.Out())
+	select {
+	case <-later.Done():
+	default:
+		t.Fatal("wait returned before the later result resolved")
+	}
+}
+
+func TestItCanReadTheResultFromManyGoroutines(t *testing.T) {
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		time.Sleep(time.Millisecond)
+		return 10, nil
+	})
+
+	wg := &sync.WaitGroup{}
+	for range 10 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			<-res.Done()
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 10, res.Out())
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, res.Err())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestResultGroupWaitContextReturnsWhenTheContextIsCancelled(t *testing.T) {
+	group := flow.ResultGroup{}
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		time.Sleep(time.Second)
+		return 1, nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, group.WaitContext(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestResultGroupCollectsTheErrors(t *testing.T) {
+	bongo := errors.New("bongo")
+	bingo := errors.New("bingo")
+
+	group := flow.ResultGroup{}
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, bongo
+	}))
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (string, error) {
+		return "", bingo
+	}))
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 1, nil
+	}))
+
+	err := group.WaitContext(context.Background())
+	assert.ErrorIs(t, err, bongo)
+	assert.ErrorIs(t, err, bingo)
+	assert.Equal(t, err, group.Err())
+}
+
+func TestResultGroupCancelsTheRestWhenOneFails(t *testing.T) {
+	bongo := errors.New("bongo")
+
+	slow := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Second):
+			return 1, nil
+		}
+	})
+
+	group := flow.ResultGroup{CancelOnError: true}
+	group.Add(slow)
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, bongo
+	}))
+
+	start := time.Now()
+	err := group.WaitContext(context.Background())
+	assert.ErrorIs(t, err, bongo)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestResultGroupCanBeAddedToConcurrently(t *testing.T) {
+	group := &flow.ResultGroup{}
+
+	wg := &sync.WaitGroup{}
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+				return i, nil
+			})))
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	for range group.Completions(context.Background()) {
+		count++
+	}
+	assert.Equal(t, 50, count)
+}
+
+func TestResultGroupWaitsForResultsAddedAfterWaiting(t *testing.T) {
+	group := &flow.ResultGroup{}
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 1, nil
+	}))
+	group.Wait()
+
+	start := time.Now()
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		time.Sleep(time.Millisecond * 5)
+		return 2, nil
+	}))
+	group.Wait()
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*5)
+}
+
+func TestResultGroupStreamsResultsAsTheyComplete(t *testing.T) {
+	slow := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		time.Sleep(time.Millisecond * 10)
+		return 1, nil
+	})
+	fast := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 2, nil
+	})
+
+	group := &flow.ResultGroup{}
+	group.Add(slow)
+	group.Add(fast)
+
+	outs := []int{}
+	for res := range group.Completions(context.Background()) {
+		outs = append(outs, res.(*flow.Result[int]).Out())
+	}
+	assert.Equal(t, []int{2, 1}, outs)
+
+	assert.Nil(t, group.Add(fast))
+}
+
+type doneOnly chan struct{}
+
+func (d doneOnly) Done() <-chan struct{} {
+	return d
+}
+
+func TestResultGroupAcceptsAnythingWithADoneChannel(t *testing.T) {
+	done := make(doneOnly)
+	close(done)
+
+	group := &flow.ResultGroup{CancelOnError: true}
+	assert.Nil(t, group.Add(done))
+	assert.Nil(t, group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errors.New("bongo")
+	})))
+	assert.EqualError(t, group.WaitContext(context.Background()), "bongo")
+}
+
+func TestResultGroupOnlySendsNewCompletions(t *testing.T) {
+	group := &flow.ResultGroup{}
+	group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 1, nil
+	}))
+	count := 0
+	for range group.Completions(context.Background()) {
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	later := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 2, nil
+	})
+	group.Add(later)
+	sent := []flow.Awaitable{}
+	for res := range group.Completions(context.Background()) {
+		sent = append(sent, res)
+	}
+	assert.Equal(t, []flow.Awaitable{later}, sent)
+}
+
+func TestResultGroupGivesBackTheResultsThatWerentSent(t *testing.T) {
+	group := &flow.ResultGroup{}
+	for i := range 2 {
+		group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+			return i, nil
+		}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	completions := group.Completions(ctx)
+	// Adding doesn't block while nobody is reading
+	assert.Nil(t, group.Add(flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 2, nil
+	})))
+	cancel()
+
+	count := 0
+	for range completions {
+		count++
+	}
+	for range group.Completions(context.Background()) {
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestTypedResultGroupStreamsTypedResults(t *testing.T) {
+	group := &flow.TypedResultGroup[int]{CancelOnError: true}
+	slow := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	failed := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errors.New("bongo")
+	})
+	group.Add(slow)
+	group.Add(failed)
+
+	sent := []*flow.Result[int]{}
+	for res := range group.Completions(context.Background()) {
+		sent = append(sent, res)
+	}
+	assert.Equal(t, []*flow.Result[int]{failed, slow}, sent)
+	assert.ErrorIs(t, slow.Err(), context.Canceled)
+	assert.ErrorContains(t, group.Err(), "bongo")
 }
@@ -0,0 +1,93 @@
+package flow
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff calculates how long to wait before the next attempt. The
+// attempt is the number of attempts made so far (starting at 1), and
+// prev is the delay that was returned for the previous attempt (0
+// before the first retry).
+type Backoff func(attempt int, prev time.Duration) time.Duration
+
+// Waits the same amount of time between every attempt
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return delay
+	}
+}
+
+// Waits initial after the first attempt, then increases the wait
+// by step after every subsequent attempt
+func LinearBackoff(initial, step time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		return initial + step*time.Duration(attempt-1)
+	}
+}
+
+// Waits base after the first attempt, then doubles the wait after
+// every subsequent attempt up to max. A max of 0 means the wait is
+// not capped.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int, _ time.Duration) time.Duration {
+		delay := base
+		for i := 1; i < attempt; i++ {
+			if delay > math.MaxInt64/2 {
+				delay = math.MaxInt64
+				break
+			}
+			delay *= 2
+		}
+		if max > 0 && delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+// Picks a random wait between 0 and the delay returned by b
+func FullJitter(b Backoff) Backoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		return jitter(b(attempt, prev))
+	}
+}
+
+// Waits half of the delay returned by b, plus a random amount
+// between 0 and the other half
+func EqualJitter(b Backoff) Backoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		half := b(attempt, prev) / 2
+		return half + jitter(half)
+	}
+}
+
+// Picks a random wait between base and three times the previous wait,
+// capped at max. A max of 0 means the wait is not capped.
+func DecorrelatedJitter(base, max time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+		upper := prev*3 - base
+		if prev > math.MaxInt64/3 {
+			upper = math.MaxInt64 - base
+		}
+		delay := base + jitter(upper)
+		if max > 0 && delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	if d < math.MaxInt64 {
+		d++
+	}
+	return rand.N(d)
+}
@@ -0,0 +1,66 @@
+package flow_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantBackoffAlwaysReturnsTheSameDelay(t *testing.T) {
+	b := flow.ConstantBackoff(time.Second)
+	assert.Equal(t, time.Second, b(1, 0))
+	assert.Equal(t, time.Second, b(5, time.Second))
+}
+
+func TestLinearBackoffIncreasesByStep(t *testing.T) {
+	b := flow.LinearBackoff(time.Second, time.Millisecond)
+	assert.Equal(t, time.Second, b(1, 0))
+	assert.Equal(t, time.Second+time.Millisecond, b(2, 0))
+	assert.Equal(t, time.Second+time.Millisecond*3, b(4, 0))
+}
+
+func TestExponentialBackoffDoublesUpToTheCap(t *testing.T) {
+	b := flow.ExponentialBackoff(time.Millisecond, time.Millisecond*5)
+	assert.Equal(t, time.Millisecond, b(1, 0))
+	assert.Equal(t, time.Millisecond*2, b(2, 0))
+	assert.Equal(t, time.Millisecond*4, b(3, 0))
+	assert.Equal(t, time.Millisecond*5, b(4, 0))
+	assert.Equal(t, time.Millisecond*5, b(100, 0))
+}
+
+func TestExponentialBackoffWithoutACapDoesntOverflow(t *testing.T) {
+	b := flow.ExponentialBackoff(time.Millisecond, 0)
+	assert.Equal(t, time.Millisecond*8, b(4, 0))
+	assert.Equal(t, time.Duration(math.MaxInt64), b(1000, 0))
+}
+
+func TestFullJitterStaysBetweenZeroAndTheDelay(t *testing.T) {
+	b := flow.FullJitter(flow.ConstantBackoff(time.Millisecond))
+	for range 100 {
+		delay := b(1, 0)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, time.Millisecond)
+	}
+}
+
+func TestEqualJitterStaysBetweenHalfAndTheDelay(t *testing.T) {
+	b := flow.EqualJitter(flow.ConstantBackoff(time.Millisecond))
+	for range 100 {
+		delay := b(1, 0)
+		assert.GreaterOrEqual(t, delay, time.Millisecond/2)
+		assert.LessOrEqual(t, delay, time.Millisecond)
+	}
+}
+
+func TestDecorrelatedJitterStaysBetweenBaseAndTheCap(t *testing.T) {
+	b := flow.DecorrelatedJitter(time.Millisecond, time.Millisecond*10)
+	var delay time.Duration
+	for i := range 100 {
+		delay = b(i+1, delay)
+		assert.GreaterOrEqual(t, delay, time.Millisecond)
+		assert.LessOrEqual(t, delay, time.Millisecond*10)
+	}
+}
@@ -0,0 +1,56 @@
+package flow
+
+import "context"
+
+// Waits for the result to resolve, or for the context to be cancelled
+func await[T any](ctx context.Context, r *Result[T]) (T, error) {
+	select {
+	case <-ctx.Done():
+		var out T
+		return out, ctx.Err()
+	case <-r.Done():
+		return r.out, r.err
+	}
+}
+
+// Returns a Result that calls f with the output of r once it has
+// resolved. If r fails, f isn't called and the new Result fails with
+// the same error. f is called with the same context as r.
+func Then[T, U any](r *Result[T], f func(context.Context, T) (U, error)) *Result[U] {
+	return Eventually(r.ctx, func(ctx context.Context) (U, error) {
+		out, err := await(ctx, r)
+		if err != nil {
+			var zero U
+			return zero, err
+		}
+		return f(ctx, out)
+	})
+}
+
+// Returns a Result that holds the output of r transformed by f
+func Map[T, U any](r *Result[T], f func(T) U) *Result[U] {
+	return Then(r, func(_ context.Context, t T) (U, error) {
+		return f(t), nil
+	})
+}
+
+// Returns a Result that calls the Effector returned by f with the
+// output of r
+func FlatMap[T, U any](r *Result[T], f func(T) Effector[U]) *Result[U] {
+	return Then(r, func(ctx context.Context, t T) (U, error) {
+		return f(t)(ctx)
+	})
+}
+
+// Returns a Result that calls f with the error from r if it fails, so
+// it can be replaced with a fallback value. If r succeeds, its output
+// is passed through. f isn't called once the context is cancelled.
+func Recover[T any](r *Result[T], f func(context.Context, error) (T, error)) *Result[T] {
+	return Eventually(r.ctx, func(ctx context.Context) (T, error) {
+		out, err := await(ctx, r)
+		if err != nil && ctx.Err() == nil {
+			return f(ctx, err)
+		}
+		return out, err
+	})
+}
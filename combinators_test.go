@@ -0,0 +1,115 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThenCallsTheFuncWithTheOutput(t *testing.T) {
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 5, nil
+	})
+
+	next := flow.Then(res, func(ctx context.Context, in int) (string, error) {
+		return strconv.Itoa(in * 2), nil
+	})
+
+	assert.Equal(t, "10", next.Out())
+	assert.Nil(t, next.Err())
+}
+
+func TestThenPassesTheErrorThrough(t *testing.T) {
+	bongo := errors.New("bongo")
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, bongo
+	})
+
+	called := false
+	next := flow.Then(res, func(ctx context.Context, in int) (int, error) {
+		called = true
+		return in, nil
+	})
+
+	assert.ErrorIs(t, next.Err(), bongo)
+	assert.False(t, called)
+}
+
+func TestThenStopsWaitingWhenTheContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	res := flow.Eventually(ctx, func(ctx context.Context) (int, error) {
+		time.Sleep(time.Second)
+		return 5, nil
+	})
+	next := flow.Then(res, func(ctx context.Context, in int) (int, error) {
+		return in, nil
+	})
+
+	start := time.Now()
+	assert.ErrorIs(t, next.Err(), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestMapTransformsTheOutput(t *testing.T) {
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 5, nil
+	})
+
+	next := flow.Map(flow.Map(res, func(in int) int {
+		return in + 1
+	}), strconv.Itoa)
+
+	assert.Equal(t, "6", next.Out())
+	assert.Nil(t, next.Err())
+}
+
+func TestFlatMapCallsTheReturnedEffector(t *testing.T) {
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 5, nil
+	})
+
+	next := flow.FlatMap(res, func(in int) flow.Effector[int] {
+		return func(ctx context.Context) (int, error) {
+			return in * in, nil
+		}
+	})
+
+	assert.Equal(t, 25, next.Out())
+}
+
+func TestRecoverReplacesTheError(t *testing.T) {
+	bongo := errors.New("bongo")
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 0, bongo
+	})
+
+	next := flow.Recover(res, func(ctx context.Context, err error) (int, error) {
+		assert.ErrorIs(t, err, bongo)
+		return 5, nil
+	})
+
+	assert.Equal(t, 5, next.Out())
+	assert.Nil(t, next.Err())
+}
+
+func TestRecoverPassesSuccessfulOutputThrough(t *testing.T) {
+	res := flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		return 5, nil
+	})
+
+	called := false
+	next := flow.Recover(res, func(ctx context.Context, err error) (int, error) {
+		called = true
+		return 0, nil
+	})
+
+	assert.Equal(t, 5, next.Out())
+	assert.False(t, called)
+}
@@ -0,0 +1,134 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type debounceOptions struct {
+	maxWait time.Duration
+	onError func(error)
+}
+
+type DebounceOption func(*debounceOptions)
+
+// Fire at least once every duration, even if the calls never stop for
+// long enough to be quiet
+func DebounceMaxWait(d time.Duration) DebounceOption {
+	return func(o *debounceOptions) {
+		o.maxWait = d
+	}
+}
+
+// Called with the error returned by the function when it is fired in
+// the background by Debounce
+func DebounceOnError(f func(error)) DebounceOption {
+	return func(o *debounceOptions) {
+		o.onError = f
+	}
+}
+
+// Return an EffectorIn that waits until it hasn't been called for the
+// duration, then fires once with the latest input (trailing edge).
+// Calls return nil straight away, and the function is fired in the
+// background with the context from the latest call, without its
+// cancellation.
+func Debounce[T any](f EffectorIn[T], wait time.Duration, opts ...DebounceOption) EffectorIn[T] {
+	d := &debouncer[T]{
+		mu:   &sync.Mutex{},
+		f:    f,
+		wait: wait,
+	}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	return d.call
+}
+
+type debouncer[T any] struct {
+	mu   *sync.Mutex
+	f    EffectorIn[T]
+	wait time.Duration
+	opts debounceOptions
+
+	timer *time.Timer
+	// Whether there is a call waiting to be fired
+	pending bool
+	// When the first call waiting to be fired was made
+	first time.Time
+	ctx   context.Context
+	in    T
+}
+
+func (d *debouncer[T]) call(ctx context.Context, t T) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if !d.pending {
+		d.pending = true
+		d.first = now
+	}
+	d.ctx = ctx
+	d.in = t
+
+	delay := d.wait
+	if d.opts.maxWait > 0 {
+		delay = max(min(delay, d.first.Add(d.opts.maxWait).Sub(now)), 0)
+	}
+	if d.timer == nil {
+		d.timer = time.AfterFunc(delay, d.fire)
+	} else {
+		d.timer.Reset(delay)
+	}
+	return nil
+}
+
+func (d *debouncer[T]) fire() {
+	d.mu.Lock()
+	if !d.pending {
+		// The timer was reset after it had already fired
+		d.mu.Unlock()
+		return
+	}
+	ctx, in := d.ctx, d.in
+	var zero T
+	d.pending = false
+	d.ctx = nil
+	d.in = zero
+	d.mu.Unlock()
+
+	if err := d.f(context.WithoutCancel(ctx), in); err != nil && d.opts.onError != nil {
+		d.opts.onError(err)
+	}
+}
+
+// Return an EffectorIn that fires straight away, then ignores calls
+// until it hasn't been called for the duration (leading edge). Ignored
+// calls return nil.
+func DebounceLeading[T any](f EffectorIn[T], wait time.Duration, opts ...DebounceOption) EffectorIn[T] {
+	o := debounceOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	mu := &sync.Mutex{}
+	var lastCall, lastFired time.Time
+
+	return func(ctx context.Context, t T) error {
+		mu.Lock()
+		now := time.Now()
+		quiet := lastCall.IsZero() || now.Sub(lastCall) >= wait
+		overdue := o.maxWait > 0 && !lastFired.IsZero() && now.Sub(lastFired) >= o.maxWait
+		lastCall = now
+		if !quiet && !overdue {
+			mu.Unlock()
+			return nil
+		}
+		lastFired = now
+		mu.Unlock()
+
+		return f(ctx, t)
+	}
+}
@@ -0,0 +1,115 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu    *sync.Mutex
+	calls []int
+}
+
+func newRecorder() *recorder {
+	return &recorder{mu: &sync.Mutex{}}
+}
+
+func (r *recorder) do(ctx context.Context, in int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, in)
+	return nil
+}
+
+func (r *recorder) get() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int{}, r.calls...)
+}
+
+func TestDebounceFiresOnceWithTheLatestInput(t *testing.T) {
+	rec := newRecorder()
+	run := flow.Debounce(rec.do, time.Millisecond*5)
+
+	for i := range 5 {
+		assert.Nil(t, run(context.Background(), i))
+	}
+	assert.Empty(t, rec.get())
+
+	time.Sleep(time.Millisecond * 20)
+
+	assert.Equal(t, []int{4}, rec.get())
+}
+
+func TestDebounceFiresWithoutTheCallersCancellation(t *testing.T) {
+	fired := make(chan error, 1)
+	run := flow.Debounce(func(ctx context.Context, in int) error {
+		fired <- ctx.Err()
+		return nil
+	}, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.Nil(t, run(ctx, 1))
+	cancel()
+
+	assert.Nil(t, <-fired)
+}
+
+func TestDebounceFiresAfterTheMaxWait(t *testing.T) {
+	rec := newRecorder()
+	run := flow.Debounce(rec.do, time.Millisecond*10, flow.DebounceMaxWait(time.Millisecond*15))
+
+	for i := range 10 {
+		assert.Nil(t, run(context.Background(), i))
+		time.Sleep(time.Millisecond * 3)
+	}
+
+	assert.NotEmpty(t, rec.get())
+}
+
+func TestDebounceReportsErrors(t *testing.T) {
+	bongo := errors.New("bongo")
+	errs := make(chan error, 1)
+	run := flow.Debounce(func(ctx context.Context, in int) error {
+		return bongo
+	}, time.Millisecond, flow.DebounceOnError(func(err error) {
+		errs <- err
+	}))
+
+	assert.Nil(t, run(context.Background(), 1))
+	assert.ErrorIs(t, <-errs, bongo)
+}
+
+func TestDebounceLeadingFiresStraightAway(t *testing.T) {
+	rec := newRecorder()
+	run := flow.DebounceLeading(rec.do, time.Millisecond*5)
+
+	for i := range 5 {
+		assert.Nil(t, run(context.Background(), i))
+	}
+	assert.Equal(t, []int{0}, rec.get())
+
+	time.Sleep(time.Millisecond * 6)
+
+	assert.Nil(t, run(context.Background(), 5))
+	assert.Equal(t, []int{0, 5}, rec.get())
+}
+
+func TestDebounceLeadingFiresAfterTheMaxWait(t *testing.T) {
+	rec := newRecorder()
+	run := flow.DebounceLeading(rec.do, time.Millisecond*200, flow.DebounceMaxWait(time.Millisecond*50))
+
+	assert.Nil(t, run(context.Background(), 0))
+	time.Sleep(time.Millisecond * 30)
+	assert.Nil(t, run(context.Background(), 1))
+	time.Sleep(time.Millisecond * 30)
+	assert.Nil(t, run(context.Background(), 2))
+
+	assert.Equal(t, []int{0, 2}, rec.get())
+}
@@ -0,0 +1,128 @@
+package flow
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNoResults = errors.New("no results")
+)
+
+// The output and error of a result once it has resolved
+type Settled[T any] struct {
+	Out T
+	Err error
+}
+
+// Returns the index of each result as it resolves, until the context
+// is cancelled
+func completions[T any](ctx context.Context, results []*Result[T]) <-chan int {
+	ch := make(chan int, len(results))
+	for i, r := range results {
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-r.Done():
+				ch <- i
+			}
+		}()
+	}
+	return ch
+}
+
+func cancelAll[T any](results []*Result[T]) {
+	for _, r := range results {
+		r.Cancel()
+	}
+}
+
+// Returns a Result that holds the outputs of every result, in the
+// same order. It fails as soon as any of them fail, and cancels the
+// rest.
+func All[T any](ctx context.Context, results ...*Result[T]) *Result[[]T] {
+	return Eventually(ctx, func(ctx context.Context) ([]T, error) {
+		done := completions(ctx, results)
+		outs := make([]T, len(results))
+		for range results {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case i := <-done:
+				if err := results[i].Err(); err != nil {
+					cancelAll(results)
+					return nil, err
+				}
+				outs[i] = results[i].Out()
+			}
+		}
+		return outs, nil
+	})
+}
+
+// Returns a Result that holds the output and error of every result,
+// in the same order, once they have all resolved
+func AllSettled[T any](ctx context.Context, results ...*Result[T]) *Result[[]Settled[T]] {
+	return Eventually(ctx, func(ctx context.Context) ([]Settled[T], error) {
+		done := completions(ctx, results)
+		settled := make([]Settled[T], len(results))
+		for range results {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case i := <-done:
+				settled[i] = Settled[T]{
+					Out: results[i].Out(),
+					Err: results[i].Err(),
+				}
+			}
+		}
+		return settled, nil
+	})
+}
+
+// Returns a Result that holds the output of the first result to
+// succeed, and cancels the rest. If they all fail, their errors are
+// joined together.
+func Any[T any](ctx context.Context, results ...*Result[T]) *Result[T] {
+	return Eventually(ctx, func(ctx context.Context) (T, error) {
+		var out T
+		if len(results) == 0 {
+			return out, ErrNoResults
+		}
+		done := completions(ctx, results)
+		errs := make([]error, 0, len(results))
+		for range results {
+			select {
+			case <-ctx.Done():
+				return out, ctx.Err()
+			case i := <-done:
+				if err := results[i].Err(); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				cancelAll(results)
+				return results[i].Out(), nil
+			}
+		}
+		return out, errors.Join(errs...)
+	})
+}
+
+// Returns a Result that holds the output and error of the first
+// result to resolve, and cancels the rest
+func Race[T any](ctx context.Context, results ...*Result[T]) *Result[T] {
+	return Eventually(ctx, func(ctx context.Context) (T, error) {
+		var out T
+		if len(results) == 0 {
+			return out, ErrNoResults
+		}
+		select {
+		case <-ctx.Done():
+			return out, ctx.Err()
+		case i := <-completions(ctx, results):
+			cancelAll(results)
+			return results[i].Out(), results[i].Err()
+		}
+	})
+}
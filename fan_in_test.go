@@ -0,0 +1,103 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+)
+
+func resolveAfter(d time.Duration, out int, err error) *flow.Result[int] {
+	return flow.Eventually(context.Background(), func(ctx context.Context) (int, error) {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(d):
+			return out, err
+		}
+	})
+}
+
+func TestAllReturnsEveryOutputInOrder(t *testing.T) {
+	res := flow.All(
+		context.Background(),
+		resolveAfter(time.Millisecond*2, 1, nil),
+		resolveAfter(0, 2, nil),
+		resolveAfter(time.Millisecond, 3, nil),
+	)
+
+	assert.Equal(t, []int{1, 2, 3}, res.Out())
+	assert.Nil(t, res.Err())
+}
+
+func TestAllFailsFastAndCancelsTheRest(t *testing.T) {
+	bongo := errors.New("bongo")
+	slow := resolveAfter(time.Second, 1, nil)
+
+	start := time.Now()
+	res := flow.All(context.Background(), slow, resolveAfter(0, 0, bongo))
+
+	assert.ErrorIs(t, res.Err(), bongo)
+	assert.ErrorIs(t, slow.Err(), context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAllSettledReturnsEveryOutputAndError(t *testing.T) {
+	bongo := errors.New("bongo")
+	res := flow.AllSettled(
+		context.Background(),
+		resolveAfter(time.Millisecond, 1, nil),
+		resolveAfter(0, 0, bongo),
+	)
+
+	assert.Nil(t, res.Err())
+	assert.Equal(t, []flow.Settled[int]{
+		{Out: 1},
+		{Err: bongo},
+	}, res.Out())
+}
+
+func TestAnyReturnsTheFirstSuccess(t *testing.T) {
+	bongo := errors.New("bongo")
+	slow := resolveAfter(time.Second, 1, nil)
+	res := flow.Any(
+		context.Background(),
+		resolveAfter(0, 0, bongo),
+		resolveAfter(time.Millisecond, 2, nil),
+		slow,
+	)
+
+	assert.Equal(t, 2, res.Out())
+	assert.Nil(t, res.Err())
+	assert.ErrorIs(t, slow.Err(), context.Canceled)
+}
+
+func TestAnyJoinsTheErrorsWhenEveryResultFails(t *testing.T) {
+	bongo := errors.New("bongo")
+	bingo := errors.New("bingo")
+	res := flow.Any(
+		context.Background(),
+		resolveAfter(0, 0, bongo),
+		resolveAfter(time.Millisecond, 0, bingo),
+	)
+
+	assert.ErrorIs(t, res.Err(), bongo)
+	assert.ErrorIs(t, res.Err(), bingo)
+}
+
+func TestRaceReturnsTheFirstToResolve(t *testing.T) {
+	bongo := errors.New("bongo")
+	slow := resolveAfter(time.Second, 1, nil)
+	res := flow.Race(context.Background(), slow, resolveAfter(0, 0, bongo))
+
+	assert.ErrorIs(t, res.Err(), bongo)
+	assert.ErrorIs(t, slow.Err(), context.Canceled)
+}
+
+func TestAnyAndRaceFailWithoutAnyResults(t *testing.T) {
+	assert.ErrorIs(t, flow.Any[int](context.Background()).Err(), flow.ErrNoResults)
+	assert.ErrorIs(t, flow.Race[int](context.Background()).Err(), flow.ErrNoResults)
+}
@@ -0,0 +1,245 @@
+package flow
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Latencies are recorded in buckets that grow by this factor,
+	// so estimates are within 10% of the real value
+	latencyGrowth = 1.1
+	// Enough buckets to go from 1µs to over an hour
+	latencyBuckets = 256
+)
+
+// A streaming histogram of latencies with logarithmic buckets
+type latencyHistogram struct {
+	counts [latencyBuckets]uint64
+	total  uint64
+}
+
+func latencyBucket(d time.Duration) int {
+	if d <= time.Microsecond {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(time.Microsecond)) / math.Log(latencyGrowth)))
+	return min(i, latencyBuckets-1)
+}
+
+// The upper bound of the bucket
+func latencyBound(i int) time.Duration {
+	return time.Duration(float64(time.Microsecond) * math.Pow(latencyGrowth, float64(i)))
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	h.counts[latencyBucket(d)]++
+	h.total++
+}
+
+type hedgerWindow struct {
+	latencies latencyHistogram
+	calls     int
+	hedged    int
+}
+
+type hedgerOptions struct {
+	percentile float64
+	maxRatio   float64
+	minSamples int
+	window     time.Duration
+}
+
+type HedgerOption func(*hedgerOptions)
+
+// The latency percentile used as the delay before starting a backup
+// attempt, between 0 and 1. Defaults to 0.95.
+func HedgerPercentile(p float64) HedgerOption {
+	return func(o *hedgerOptions) {
+		o.percentile = p
+	}
+}
+
+// The maximum ratio of calls that can start backup attempts, between
+// 0 and 1. Defaults to 0.1.
+func HedgerMaxRatio(r float64) HedgerOption {
+	return func(o *hedgerOptions) {
+		o.maxRatio = r
+	}
+}
+
+// The number of latencies that need to be recorded before any calls
+// are hedged. Defaults to 20.
+func HedgerMinSamples(n int) HedgerOption {
+	return func(o *hedgerOptions) {
+		o.minSamples = n
+	}
+}
+
+// How long latencies are remembered for, estimates are made from the
+// current and previous window. Defaults to 1 minute.
+func HedgerWindow(d time.Duration) HedgerOption {
+	return func(o *hedgerOptions) {
+		o.window = d
+	}
+}
+
+// Hedges calls to an effector, using the latencies it has seen to
+// decide when to start backup attempts. Backups are only started for
+// calls that are slower than the configured percentile, and only for
+// up to the max ratio of calls. It is safe for concurrent use.
+type Hedger[T any] struct {
+	mu    *sync.Mutex
+	f     Effector[T]
+	count int
+	opts  hedgerOptions
+
+	current  *hedgerWindow
+	previous *hedgerWindow
+	rotated  time.Time
+}
+
+// Create a Hedger that makes up to count attempts for each call
+func NewHedger[T any](f Effector[T], count int, opts ...HedgerOption) *Hedger[T] {
+	o := hedgerOptions{
+		percentile: 0.95,
+		maxRatio:   0.1,
+		minSamples: 20,
+		window:     time.Minute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Hedger[T]{
+		mu:       &sync.Mutex{},
+		f:        f,
+		count:    count,
+		opts:     o,
+		current:  &hedgerWindow{},
+		previous: &hedgerWindow{},
+		rotated:  time.Now(),
+	}
+}
+
+// Call the effector, starting a backup attempt each time the hedge
+// delay passes without one succeeding
+func (h *Hedger[T]) Do(ctx context.Context) (T, error) {
+	h.mu.Lock()
+	h.rotate()
+	h.current.calls++
+	delay, ok := h.delay()
+	h.mu.Unlock()
+
+	if !ok {
+		return h.attempt(ctx, true)
+	}
+	// The first attempt to start is the primary
+	started := &atomic.Bool{}
+	hedged := false
+	return hedge(ctx, func(ctx context.Context) (T, error) {
+		return h.attempt(ctx, started.CompareAndSwap(false, true))
+	}, h.count, hedgeConfig[T]{
+		delay: delay,
+		// The ratio is of calls, so a call only counts once however
+		// many backups it starts
+		backup: func() bool {
+			if !hedged {
+				hedged = h.allowBackup()
+			}
+			return hedged
+		},
+	})
+}
+
+// The current delay before a backup attempt is started, returns 0 if
+// not enough latencies have been recorded to start hedging
+func (h *Hedger[T]) Delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rotate()
+	delay, _ := h.delay()
+	return delay
+}
+
+// The estimated latency at the percentile (between 0 and 1), returns 0
+// if no latencies have been recorded
+func (h *Hedger[T]) Percentile(p float64) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rotate()
+	return h.percentile(p)
+}
+
+// Records the latency of the attempt. A primary that is cancelled is
+// still recorded, as the time until then is a lower bound of its
+// latency. Backups that are cancelled aren't, as they only show how
+// long until the primary finished.
+func (h *Hedger[T]) attempt(ctx context.Context, primary bool) (T, error) {
+	start := time.Now()
+	out, err := h.f(ctx)
+	if primary || ctx.Err() == nil {
+		h.mu.Lock()
+		h.rotate()
+		h.current.latencies.record(time.Since(start))
+		h.mu.Unlock()
+	}
+	return out, err
+}
+
+func (h *Hedger[T]) allowBackup() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rotate()
+
+	calls := h.current.calls + h.previous.calls
+	hedged := h.current.hedged + h.previous.hedged
+	if float64(hedged+1) > h.opts.maxRatio*float64(calls) {
+		return false
+	}
+	h.current.hedged++
+	return true
+}
+
+func (h *Hedger[T]) delay() (time.Duration, bool) {
+	if h.current.latencies.total+h.previous.latencies.total < uint64(max(h.opts.minSamples, 1)) {
+		return 0, false
+	}
+	return h.percentile(h.opts.percentile), true
+}
+
+func (h *Hedger[T]) percentile(p float64) time.Duration {
+	total := h.current.latencies.total + h.previous.latencies.total
+	if total == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(min(max(p, 0), 1) * float64(total)))
+	var seen uint64
+	for i := range latencyBuckets {
+		seen += h.current.latencies.counts[i] + h.previous.latencies.counts[i]
+		if seen >= max(target, 1) {
+			return latencyBound(i)
+		}
+	}
+	return latencyBound(latencyBuckets - 1)
+}
+
+// Moves on to a new window once the current one has passed
+func (h *Hedger[T]) rotate() {
+	elapsed := time.Since(h.rotated)
+	if elapsed < h.opts.window {
+		return
+	}
+	if elapsed >= h.opts.window*2 {
+		// Nothing has happened for a whole window, so the current
+		// window is too old to use
+		h.previous = &hedgerWindow{}
+	} else {
+		h.previous = h.current
+	}
+	h.current = &hedgerWindow{}
+	h.rotated = time.Now()
+}
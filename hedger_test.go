@@ -0,0 +1,145 @@
+package flow
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyHistogramBucketsAreWithinTheGrowthFactor(t *testing.T) {
+	for _, d := range []time.Duration{time.Microsecond * 3, time.Millisecond, time.Millisecond * 37, time.Second * 5} {
+		bound := latencyBound(latencyBucket(d))
+		require.GreaterOrEqual(t, bound, d)
+		require.LessOrEqual(t, float64(bound), float64(d)*latencyGrowth)
+	}
+}
+
+func TestHedgerEstimatesPercentiles(t *testing.T) {
+	h := NewHedger(func(ctx context.Context) (int, error) {
+		return 1, nil
+	}, 2)
+
+	h.mu.Lock()
+	for i := 1; i <= 100; i++ {
+		h.current.latencies.record(time.Duration(i) * time.Millisecond)
+	}
+	h.mu.Unlock()
+
+	require.InEpsilon(t, float64(time.Millisecond*50), float64(h.Percentile(0.5)), 0.1)
+	require.InEpsilon(t, float64(time.Millisecond*95), float64(h.Percentile(0.95)), 0.1)
+	require.Equal(t, h.Percentile(0.95), h.Delay())
+}
+
+func TestHedgerDoesntHedgeUntilItHasEnoughSamples(t *testing.T) {
+	calls := &atomic.Int32{}
+	h := NewHedger(func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		return 1, nil
+	}, 3, HedgerMinSamples(5))
+
+	for range 4 {
+		_, err := h.Do(context.Background())
+		require.Nil(t, err)
+	}
+	require.Equal(t, int32(4), calls.Load())
+	require.Equal(t, time.Duration(0), h.Delay())
+
+	_, err := h.Do(context.Background())
+	require.Nil(t, err)
+	require.NotZero(t, h.Delay())
+}
+
+func TestHedgerStartsABackupForSlowCalls(t *testing.T) {
+	calls := &atomic.Int32{}
+	slow := &atomic.Bool{}
+	h := NewHedger(func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		if slow.CompareAndSwap(true, false) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 1, nil
+	}, 2, HedgerMinSamples(1), HedgerMaxRatio(1))
+
+	_, err := h.Do(context.Background())
+	require.Nil(t, err)
+
+	slow.Store(true)
+	start := time.Now()
+	out, err := h.Do(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, out)
+	require.Equal(t, int32(3), calls.Load())
+	require.Less(t, time.Since(start), time.Second)
+
+	// The cancelled slow primary is recorded as well as the backup
+	require.Eventually(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return h.current.latencies.total+h.previous.latencies.total == 3
+	}, time.Second, time.Millisecond)
+}
+
+func TestHedgerDoesntRecordCancelledBackups(t *testing.T) {
+	calls := &atomic.Int32{}
+	h := NewHedger(func(ctx context.Context) (int, error) {
+		switch calls.Add(1) {
+		case 1:
+			return 1, nil
+		case 2:
+			time.Sleep(time.Millisecond * 5)
+			return 1, nil
+		default:
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+	}, 2, HedgerMinSamples(1), HedgerMaxRatio(1))
+
+	_, err := h.Do(context.Background())
+	require.Nil(t, err)
+	_, err = h.Do(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, int32(3), calls.Load())
+
+	// Give the cancelled backup time to return
+	time.Sleep(time.Millisecond * 10)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	require.Equal(t, uint64(2), h.current.latencies.total+h.previous.latencies.total)
+}
+
+func TestHedgerCountsEachHedgedCallOnce(t *testing.T) {
+	calls := &atomic.Int32{}
+	h := NewHedger(func(ctx context.Context) (int, error) {
+		if calls.Add(1) > 1 {
+			time.Sleep(time.Millisecond * 5)
+		}
+		return 1, nil
+	}, 3, HedgerMinSamples(1), HedgerMaxRatio(1))
+
+	_, err := h.Do(context.Background())
+	require.Nil(t, err)
+	_, err = h.Do(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, int32(4), calls.Load())
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	require.Equal(t, 1, h.current.hedged+h.previous.hedged)
+}
+
+func TestHedgerCapsTheRatioOfHedgedCalls(t *testing.T) {
+	h := NewHedger(func(ctx context.Context) (int, error) {
+		return 1, nil
+	}, 2, HedgerMaxRatio(0.5))
+
+	for range 4 {
+		h.Do(context.Background())
+	}
+	require.True(t, h.allowBackup())
+	require.True(t, h.allowBackup())
+	require.False(t, h.allowBackup())
+}
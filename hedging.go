@@ -2,38 +2,139 @@ package flow
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 )
 
+// Calls the effector count times at once, and returns the result of
+// the first attempt to succeed. The other attempts are cancelled once
+// one succeeds. If every attempt fails, the errors from all of them
+// are joined together and returned.
 func Hedge[T any](ctx context.Context, f Effector[T], count int) (T, error) {
-	ops := []context.CancelFunc{}
+	return hedge(ctx, f, count, hedgeConfig[T]{})
+}
+
+type hedgeResult[T any] struct {
+	out T
+	err error
+}
+
+// Does the same as Hedge, but only the first attempt is started
+// straight away. Another attempt is started every time the delay
+// passes, or as soon as an attempt fails, until count attempts have
+// been started.
+func HedgeDelayed[T any](ctx context.Context, f Effector[T], count int, delay time.Duration) (T, error) {
+	return hedge(ctx, f, count, hedgeConfig[T]{delay: delay})
+}
+
+type hedgeConfig[T any] struct {
+	// How long to wait before starting each backup attempt
+	delay time.Duration
+	// Called before starting each backup attempt, returning false
+	// stops any more from being started
+	backup func() bool
+	// Called with the successful results that weren't returned
+	discard func(T)
+}
 
-	respCh := make(chan T, 1)
-	errCh := make(chan error, 1)
+func hedge[T any](ctx context.Context, f Effector[T], count int, o hedgeConfig[T]) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	delay, backup := o.delay, o.backup
 
-	for range count {
-		ctx, cancel := context.WithCancel(ctx)
-		ops = append(ops, cancel)
+	count = max(count, 1)
+	// Buffered so that attempts that finish after we have returned
+	// don't block forever
+	results := make(chan hedgeResult[T], count)
+	started := 0
+	start := func() bool {
+		if started > 0 && backup != nil && !backup() {
+			count = started
+			return false
+		}
+		started++
 		go func() {
-			resp, err := f(ctx)
-			respCh <- resp
-			errCh <- err
+			out, err := f(ctx)
+			results <- hedgeResult[T]{out: out, err: err}
 		}()
+		return true
 	}
 
-	resp := <-respCh
-	err := <-errCh
+	start()
+	if delay <= 0 {
+		for started < count && start() {
+		}
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	received := 0
+	errs := []error{}
+	// Cleans up the results of the attempts that are still running
+	// once we have returned
+	defer func() {
+		if o.discard == nil {
+			return
+		}
+		go func(remaining int) {
+			for range remaining {
+				if res := <-results; res.err == nil {
+					o.discard(res.out)
+				}
+			}
+		}(started - received)
+	}()
+
+	for {
+		var next <-chan time.Time
+		if started < count {
+			next = timer.C
+		}
 
-	for _, cancel := range ops {
-		cancel()
+		select {
+		case <-ctx.Done():
+			var out T
+			return out, ctx.Err()
+		case <-next:
+			if start() {
+				timer.Reset(delay)
+			}
+		case res := <-results:
+			received++
+			if res.err == nil {
+				return res.out, nil
+			}
+			errs = append(errs, res.err)
+			if started < count && start() {
+				resetTimer(timer, delay)
+			}
+			if len(errs) == started {
+				var out T
+				return out, errors.Join(errs...)
+			}
+		}
 	}
+}
 
-	return resp, err
+// Resets a timer that may have already fired without being read
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
 }
 
 type HedgeClient struct {
 	c     *http.Client
 	count int
+
+	// By default, only requests with idempotent methods are hedged.
+	// Set this to hedge every request that has a rewindable body.
+	AllowNonIdempotent bool
 }
 
 func NewHedgeClient(c *http.Client, count int) *HedgeClient {
@@ -43,8 +144,61 @@ func NewHedgeClient(c *http.Client, count int) *HedgeClient {
 	}
 }
 
+// Sends a copy of the request for each attempt, and returns the first
+// successful response. The bodies of the other responses are closed.
 func (h *HedgeClient) Do(r *http.Request) (*http.Response, error) {
-	return Hedge(r.Context(), func(ctx context.Context) (*http.Response, error) {
+	if (!h.AllowNonIdempotent && !idempotent(r)) || !rewindable(r) {
 		return h.c.Do(r)
-	}, h.count)
+	}
+	return hedgeRequest(r, h.count, 0, h.c.Do)
+}
+
+// An http.RoundTripper that hedges requests, sending a copy of the
+// request for each attempt and returning the first successful
+// response. The bodies of the other responses are closed.
+type HedgeTransport struct {
+	// The transport used to make each attempt, http.DefaultTransport
+	// is used when this is nil
+	Next http.RoundTripper
+	// The maximum number of attempts for each request
+	Count int
+	// How long to wait before starting each backup attempt, they are
+	// all started at once when this is 0
+	Delay time.Duration
+	// By default, only requests with idempotent methods are hedged.
+	// Set this to hedge every request that has a rewindable body.
+	AllowNonIdempotent bool
+}
+
+func NewHedgeTransport(next http.RoundTripper, count int) *HedgeTransport {
+	return &HedgeTransport{
+		Next:  next,
+		Count: count,
+	}
+}
+
+func (t *HedgeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if (!t.AllowNonIdempotent && !idempotent(r)) || !rewindable(r) {
+		return next.RoundTrip(r)
+	}
+	return hedgeRequest(r, t.Count, t.Delay, next.RoundTrip)
+}
+
+// Hedges the request, each attempt gets its own copy of the request
+// and body
+func hedgeRequest(r *http.Request, count int, delay time.Duration, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if r.Body != nil {
+		// Every attempt reads a body from GetBody instead
+		defer r.Body.Close()
+	}
+	return hedge(r.Context(), func(ctx context.Context) (*http.Response, error) {
+		return sendAttempt(ctx, r, true, do)
+	}, count, hedgeConfig[*http.Response]{
+		delay:   delay,
+		discard: discard,
+	})
 }
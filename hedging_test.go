@@ -2,6 +2,11 @@ package flow
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -51,3 +56,227 @@ func TestItCancelsOtherRequestsAfterOneFinshes(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, int32(1), hits.Load())
 }
+
+func TestHedgeDelayedOnlyStartsBackupsAfterTheDelay(t *testing.T) {
+	count := &atomic.Int32{}
+
+	_, err := HedgeDelayed(context.Background(), dummyHedged{
+		do: func(ctx context.Context) {
+			count.Add(1)
+		},
+	}.Do, 3, time.Millisecond*50)
+	require.Nil(t, err)
+	time.Sleep(time.Millisecond)
+	require.Equal(t, int32(1), count.Load())
+}
+
+func TestHedgeDelayedStartsABackupWhenTheFirstIsSlow(t *testing.T) {
+	iters := &atomic.Int32{}
+
+	start := time.Now()
+	_, err := HedgeDelayed(context.Background(), dummyHedged{
+		do: func(ctx context.Context) {
+			if iters.Add(1) == 1 {
+				<-ctx.Done()
+			}
+		},
+	}.Do, 3, time.Millisecond*5)
+	require.Nil(t, err)
+	require.Equal(t, int32(2), iters.Load())
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestHedgeDelayedStartsABackupWhenAnAttemptFails(t *testing.T) {
+	iters := &atomic.Int32{}
+
+	out, err := HedgeDelayed(context.Background(), func(ctx context.Context) (int32, error) {
+		iter := iters.Add(1)
+		if iter == 1 {
+			return 0, errors.New("bongo")
+		}
+		return iter, nil
+	}, 3, time.Hour)
+	require.Nil(t, err)
+	require.Equal(t, int32(2), out)
+}
+
+func TestHedgeDelayedReturnsAnErrorWhenEveryAttemptFails(t *testing.T) {
+	iters := &atomic.Int32{}
+
+	_, err := HedgeDelayed(context.Background(), func(ctx context.Context) (int, error) {
+		iters.Add(1)
+		return 0, errors.New("bongo")
+	}, 3, time.Hour)
+	require.NotNil(t, err)
+	require.Equal(t, int32(3), iters.Load())
+}
+
+func TestHedgeReturnsTheFirstSuccessNotTheFirstToFinish(t *testing.T) {
+	iters := &atomic.Int32{}
+
+	out, err := Hedge(context.Background(), func(ctx context.Context) (int32, error) {
+		iter := iters.Add(1)
+		if iter == 1 {
+			return 0, errors.New("bongo")
+		}
+		time.Sleep(time.Millisecond)
+		return iter, nil
+	}, 2)
+	require.Nil(t, err)
+	require.Equal(t, int32(2), out)
+}
+
+func TestHedgeJoinsTheErrorsWhenEveryAttemptFails(t *testing.T) {
+	bongo := errors.New("bongo")
+	bingo := errors.New("bingo")
+	iters := &atomic.Int32{}
+
+	_, err := Hedge(context.Background(), func(ctx context.Context) (int, error) {
+		if iters.Add(1) == 1 {
+			return 0, bongo
+		}
+		return 0, bingo
+	}, 2)
+	require.ErrorIs(t, err, bongo)
+	require.ErrorIs(t, err, bingo)
+}
+
+type trackedBody struct {
+	io.Reader
+	closed *atomic.Int32
+}
+
+func (t trackedBody) Close() error {
+	t.closed.Add(1)
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestHedgeClientSendsTheBodyWithEveryAttempt(t *testing.T) {
+	// Hold every response until all the attempts have arrived, otherwise
+	// the first response cancels the attempts that haven't been sent yet
+	arrived := make(chan struct{})
+	calls := &atomic.Int32{}
+	wrong := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "bongo" {
+			wrong.Add(1)
+		}
+		if calls.Add(1) == 3 {
+			close(arrived)
+		}
+		select {
+		case <-arrived:
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := NewHedgeClient(srv.Client(), 3)
+	client.AllowNonIdempotent = true
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("bongo"))
+	require.Nil(t, err)
+	resp, err := client.Do(req)
+	require.Nil(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, int32(3), calls.Load())
+	require.Equal(t, int32(0), wrong.Load())
+}
+
+func TestHedgeClientOnlySendsNonIdempotentRequestsOnce(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	client := NewHedgeClient(srv.Client(), 3)
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("bongo"))
+	require.Nil(t, err)
+	resp, err := client.Do(req)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestHedgeClientClosesTheLosingResponses(t *testing.T) {
+	closed := &atomic.Int32{}
+	iters := &atomic.Int32{}
+	c := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if iters.Add(1) > 1 {
+				time.Sleep(time.Millisecond * 5)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       trackedBody{Reader: strings.NewReader("bongo"), closed: closed},
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://bongo", nil)
+	require.Nil(t, err)
+	resp, err := NewHedgeClient(c, 3).Do(req)
+	require.Nil(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.Nil(t, err)
+	require.Equal(t, "bongo", string(body))
+
+	require.Eventually(t, func() bool {
+		return closed.Load() == 2
+	}, time.Second, time.Millisecond)
+	resp.Body.Close()
+	require.Equal(t, int32(3), closed.Load())
+}
+
+func TestHedgeTransportCanBeUsedByAnyClient(t *testing.T) {
+	arrived := make(chan struct{})
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 3 {
+			close(arrived)
+		}
+		select {
+		case <-arrived:
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte("bongo"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewHedgeTransport(srv.Client().Transport, 3)}
+	resp, err := client.Get(srv.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.Nil(t, err)
+	require.Equal(t, "bongo", string(body))
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestHedgeTransportWaitsForTheDelayBeforeStartingBackups(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	transport := NewHedgeTransport(srv.Client().Transport, 3)
+	transport.Delay = time.Second
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(srv.URL)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, int32(1), calls.Load())
+}
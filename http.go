@@ -0,0 +1,75 @@
+package flow
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Sends a copy of the request with do. The ctx can only cancel the
+// request until the response has been received, after that the body
+// is cancelled along with the original request or when it is closed.
+// This lets attempts be cancelled as soon as they return without
+// breaking the body of the response they returned. When rewind is
+// true, the copy is sent with a new body from GetBody.
+func sendAttempt(ctx context.Context, r *http.Request, rewind bool, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	rctx, cancel := context.WithCancel(r.Context())
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	req := r.Clone(rctx)
+	if rewind && r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			cancel()
+			return nil, Permanent(err)
+		}
+		req.Body = body
+	}
+
+	resp, err := do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// Cancels the context of the request when the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelBody) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// Whether the request can safely be sent more than once
+func idempotent(r *http.Request) bool {
+	switch r.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if _, ok := r.Header["Idempotency-Key"]; ok {
+		return true
+	}
+	if _, ok := r.Header["X-Idempotency-Key"]; ok {
+		return true
+	}
+	return false
+}
+
+// Whether the request body can be read again for another attempt
+func rewindable(r *http.Request) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// Reads the rest of the response body so the connection can be
+// reused, then closes it
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
@@ -0,0 +1,151 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// What a rate limited function does when there are no tokens left
+type LimitMode int
+
+const (
+	// Return ErrThrottled straight away
+	LimitReject LimitMode = iota
+	// Wait for a token to become available, or until the context
+	// is cancelled
+	LimitWait
+)
+
+// A token bucket rate limiter. The bucket holds up to burst tokens and
+// gains a new token every duration, each call takes a token from the
+// bucket. It is safe for concurrent use, and can be shared between
+// multiple functions.
+type RateLimiter struct {
+	mu *sync.Mutex
+
+	every time.Duration
+	burst int
+
+	tokens float64
+	last   time.Time
+}
+
+// Create a limiter that allows one call every duration, with bursts of
+// up to burst calls
+func NewRateLimiter(every time.Duration, burst int) *RateLimiter {
+	burst = max(burst, 1)
+	return &RateLimiter{
+		mu:     &sync.Mutex{},
+		every:  every,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Takes a token if one is available, returns false if there isn't
+func (l *RateLimiter) Allow() bool {
+	ok, _ := l.allow()
+	return ok
+}
+
+// Takes a token if one is available, if there isn't it returns how
+// long until there will be
+func (l *RateLimiter) allow() (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false, time.Duration((1 - l.tokens) * float64(l.every))
+	}
+	l.tokens--
+	return true, 0
+}
+
+// Blocks until a token is available and takes it. If the context is
+// cancelled before then, the token is given back and the context error
+// is returned. If the context deadline is before the token would be
+// available, it returns straight away.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	// Reserve a token, if we go negative then we are in the queue
+	// for the next available ones
+	l.tokens--
+	delay := l.delay()
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		l.tokens++
+		l.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+	l.mu.Unlock()
+
+	if err := wait(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// How long until the reserved tokens have been paid back
+func (l *RateLimiter) delay() time.Duration {
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.every))
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	if l.every <= 0 {
+		l.tokens = float64(l.burst)
+		l.last = now
+		return
+	}
+	l.tokens += float64(now.Sub(l.last)) / float64(l.every)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+}
+
+// Return an Effector that takes a token from the limiter before every
+// call
+func RateLimit[T any](f Effector[T], l *RateLimiter, mode LimitMode) Effector[T] {
+	return func(ctx context.Context) (T, error) {
+		if err := l.take(ctx, mode); err != nil {
+			var out T
+			return out, err
+		}
+		return f(ctx)
+	}
+}
+
+// Return an EffectorIn that takes a token from the limiter before
+// every call
+func RateLimitIn[T any](f EffectorIn[T], l *RateLimiter, mode LimitMode) EffectorIn[T] {
+	return func(ctx context.Context, t T) error {
+		if err := l.take(ctx, mode); err != nil {
+			return err
+		}
+		return f(ctx, t)
+	}
+}
+
+func (l *RateLimiter) take(ctx context.Context, mode LimitMode) error {
+	if mode == LimitWait {
+		return l.Wait(ctx)
+	}
+	if ok, after := l.allow(); !ok {
+		return throttled(after)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package flow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterAllowsABurst(t *testing.T) {
+	limiter := flow.NewRateLimiter(time.Hour, 3)
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+}
+
+func TestRateLimiterRefillsTokens(t *testing.T) {
+	limiter := flow.NewRateLimiter(time.Millisecond*5, 1)
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	time.Sleep(time.Millisecond * 6)
+
+	assert.True(t, limiter.Allow())
+}
+
+func TestRateLimiterWaitsForATokenToBeAvailable(t *testing.T) {
+	limiter := flow.NewRateLimiter(time.Millisecond*5, 1)
+	start := time.Now()
+	assert.Nil(t, limiter.Wait(context.Background()))
+	assert.Nil(t, limiter.Wait(context.Background()))
+	assert.Nil(t, limiter.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*10)
+}
+
+func TestRateLimiterStopsWaitingWhenTheContextIsCancelled(t *testing.T) {
+	limiter := flow.NewRateLimiter(time.Hour, 1)
+	assert.True(t, limiter.Allow())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond)
+		cancel()
+	}()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
+
+func TestRateLimitRejectsCallsWithoutAToken(t *testing.T) {
+	calls := 0
+	do := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	run := flow.RateLimit(do, flow.NewRateLimiter(time.Hour, 1), flow.LimitReject)
+	out, err := run(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, out)
+
+	out, err = run(context.Background())
+	assert.ErrorIs(t, err, flow.ErrThrottled)
+	assert.Empty(t, out)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRateLimitInWaitsForAToken(t *testing.T) {
+	set := 0
+	do := func(ctx context.Context, in int) error {
+		set += in
+		return nil
+	}
+
+	run := flow.RateLimitIn(do, flow.NewRateLimiter(time.Millisecond*5, 1), flow.LimitWait)
+	start := time.Now()
+	assert.Nil(t, run(context.Background(), 1))
+	assert.Nil(t, run(context.Background(), 1))
+	assert.Equal(t, 2, set)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*5)
+}
@@ -2,36 +2,265 @@ package flow
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 )
 
-// Retries calling the effector x times if it fails
-func Retry[T any](f Effector[T], times int) Effector[T] {
-	return func(ctx context.Context) (T, error) {
-		var out T
-		var err error
-		for i := 0; i < times; i++ {
-			out, err = f(ctx)
-			if err == nil {
-				return out, nil
-			}
-		}
-		return out, err
+var (
+	ErrAttemptTimeout = errors.New("attempt timed out")
+)
+
+// Wraps an error to mark it as permanent, so retries stop
+// immediately instead of trying again
+type PermanentError struct {
+	Err error
+}
+
+func (p *PermanentError) Error() string {
+	return p.Err.Error()
+}
+
+func (p *PermanentError) Unwrap() error {
+	return p.Err
+}
+
+// Marks the error as permanent, returns nil if err is nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// The result of a single attempt made by a retry
+type RetryAttempt struct {
+	// The error returned by the attempt
+	Err error
+	// When the attempt started
+	Start time.Time
+	// How long the attempt took to return
+	Duration time.Duration
+}
+
+// Returned when a retry gives up, holds the errors from every
+// attempt that was made. The error message is the same as the
+// error from the last attempt.
+type RetryError struct {
+	Attempts []RetryAttempt
+}
+
+func (r *RetryError) Error() string {
+	if len(r.Attempts) == 0 {
+		return "retry made no attempts"
+	}
+	return r.Last().Error()
+}
+
+// Returns the error from every attempt so they can be matched
+// with errors.Is and errors.As
+func (r *RetryError) Unwrap() []error {
+	errs := make([]error, len(r.Attempts))
+	for i, a := range r.Attempts {
+		errs[i] = a.Err
+	}
+	return errs
+}
+
+// Returns the error from the last attempt, or nil if no attempts
+// were made
+func (r *RetryError) Last() error {
+	if len(r.Attempts) == 0 {
+		return nil
+	}
+	return r.Attempts[len(r.Attempts)-1].Err
+}
+
+// Errors that implement this will be retried after the returned
+// duration instead of the one calculated by the backoff
+type retryAfter interface {
+	RetryAfter() time.Duration
+}
+
+type retryOptions struct {
+	retryable func(error) bool
+	budget    *RetryBudget
+	onRetry   []func(attempt int, err error, next time.Duration)
+	onGiveUp  []func(attempts int, err error)
+	timeout   time.Duration
+}
+
+type RetryOption func(*retryOptions)
+
+// Only retry when f returns true for the error from the last attempt.
+// Errors marked with Permanent are never retried.
+func RetryIf(f func(error) bool) RetryOption {
+	return func(o *retryOptions) {
+		o.retryable = f
+	}
+}
+
+// Only retry while the budget allows it. When the budget is exhausted
+// the returned error wraps ErrRetryBudgetExhausted.
+func RetryWithBudget(b *RetryBudget) RetryOption {
+	return func(o *retryOptions) {
+		o.budget = b
+	}
+}
+
+// Called after an attempt fails and is going to be retried, with
+// the number of the failed attempt, its error and the delay before
+// the next attempt
+func OnRetry(f func(attempt int, err error, next time.Duration)) RetryOption {
+	return func(o *retryOptions) {
+		o.onRetry = append(o.onRetry, f)
 	}
 }
 
+// Called when the retry gives up, with the number of attempts made
+// and the error that will be returned
+func OnGiveUp(f func(attempts int, err error)) RetryOption {
+	return func(o *retryOptions) {
+		o.onGiveUp = append(o.onGiveUp, f)
+	}
+}
+
+// Gives each attempt its own context that times out after the
+// duration. An attempt that times out is retried unless its error is
+// marked as permanent, and its error wraps ErrAttemptTimeout, but the
+// retry still stops when the context passed to it is done.
+func AttemptTimeout(d time.Duration) RetryOption {
+	return func(o *retryOptions) {
+		o.timeout = d
+	}
+}
+
+func (o *retryOptions) shouldRetry(err error) bool {
+	var perm *PermanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	if errors.Is(err, ErrAttemptTimeout) {
+		return true
+	}
+	if o.retryable != nil {
+		return o.retryable(err)
+	}
+	return true
+}
+
+// Retries calling the effector x times if it fails
+func Retry[T any](f Effector[T], times int, opts ...RetryOption) Effector[T] {
+	return RetryBackoff(f, times, ConstantBackoff(0), opts...)
+}
+
 // Does the same a Retry, but adds a delay after each attempt
-func RetryDelay[T any](f Effector[T], times int, delay time.Duration) Effector[T] {
+func RetryDelay[T any](f Effector[T], times int, delay time.Duration, opts ...RetryOption) Effector[T] {
+	return RetryBackoff(f, times, ConstantBackoff(delay), opts...)
+}
+
+// Does the same as Retry, but waits for the delay calculated by the
+// backoff between each attempt. If the context is cancelled it stops
+// retrying and returns an error wrapping both the context error and
+// the error from the last attempt.
+func RetryBackoff[T any](f Effector[T], times int, backoff Backoff, opts ...RetryOption) Effector[T] {
+	o := &retryOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return func(ctx context.Context) (T, error) {
 		var out T
-		var err error
-		for i := 0; i < times; i++ {
-			out, err = f(ctx)
+		var delay time.Duration
+		rerr := &RetryError{}
+		if o.budget != nil {
+			o.budget.RecordRequest()
+		}
+		giveUp := func(err error) (T, error) {
+			for _, f := range o.onGiveUp {
+				f(len(rerr.Attempts), err)
+			}
+			return out, err
+		}
+		for i := 1; i <= times; i++ {
+			if ctx.Err() != nil {
+				return giveUp(retryCancelled(ctx, rerr))
+			}
+			start := time.Now()
+			var err error
+			out, err = attempt(ctx, f, o.timeout)
 			if err == nil {
 				return out, nil
 			}
-			time.Sleep(delay)
+			rerr.Attempts = append(rerr.Attempts, RetryAttempt{
+				Err:      err,
+				Start:    start,
+				Duration: time.Since(start),
+			})
+			// Don't take from the budget or call the retry hooks for a
+			// retry that would never be made
+			if ctx.Err() != nil {
+				return giveUp(retryCancelled(ctx, rerr))
+			}
+			if i == times || !o.shouldRetry(err) {
+				break
+			}
+			if o.budget != nil && !o.budget.AllowRetry() {
+				return giveUp(fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, rerr))
+			}
+			delay = backoff(i, delay)
+			var ra retryAfter
+			if errors.As(err, &ra) && ra.RetryAfter() > 0 {
+				delay = ra.RetryAfter()
+			}
+			for _, f := range o.onRetry {
+				f(i, err, delay)
+			}
+			if wait(ctx, delay) != nil {
+				return giveUp(retryCancelled(ctx, rerr))
+			}
+		}
+		if len(rerr.Attempts) == 0 {
+			return out, nil
 		}
-		return out, err
+		return giveUp(rerr)
+	}
+}
+
+// Calls the effector, with its own timeout if one is set
+func attempt[T any](ctx context.Context, f Effector[T], timeout time.Duration) (T, error) {
+	if timeout <= 0 {
+		return f(ctx)
+	}
+
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := f(actx)
+	if err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrAttemptTimeout, err)
+	}
+	return out, err
+}
+
+func retryCancelled(ctx context.Context, rerr *RetryError) error {
+	if len(rerr.Attempts) == 0 {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w: %w", ctx.Err(), rerr)
+}
+
+// Blocks for the duration, or until the context is cancelled
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
@@ -0,0 +1,95 @@
+package flow
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+)
+
+// The number of buckets the budget window is split into
+const retryBudgetBuckets = 10
+
+// Limits the number of retries to a ratio of the requests made over a
+// sliding window, so that retries stop when a dependency is down
+// instead of multiplying the load on it. A budget can be shared
+// between any number of retries.
+type RetryBudget struct {
+	mu *sync.Mutex
+
+	ratio float64
+	min   int
+	width time.Duration
+
+	buckets [retryBudgetBuckets]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	epoch    int64
+	requests int
+	retries  int
+}
+
+// Create a budget that allows retries to make up the given ratio of
+// requests over the window (e.g. 0.1 for 10%), plus min retries per
+// window so that low traffic callers can still retry
+func NewRetryBudget(ratio float64, min int, window time.Duration) *RetryBudget {
+	width := window / retryBudgetBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &RetryBudget{
+		mu:    &sync.Mutex{},
+		ratio: ratio,
+		min:   min,
+		width: width,
+	}
+}
+
+// Record a request against the budget
+func (b *RetryBudget) RecordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now()).requests++
+}
+
+// Reports whether there is enough budget left to retry, and records
+// the retry against the budget if there is
+func (b *RetryBudget) AllowRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	requests, retries := b.totals(now)
+	if float64(retries) >= float64(b.min)+float64(requests)*b.ratio {
+		return false
+	}
+	b.bucket(now).retries++
+	return true
+}
+
+// Returns the bucket for the time, resetting it if it was last used
+// in a previous window
+func (b *RetryBudget) bucket(now time.Time) *retryBudgetBucket {
+	epoch := now.UnixNano() / int64(b.width)
+	bucket := &b.buckets[epoch%retryBudgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = retryBudgetBucket{epoch: epoch}
+	}
+	return bucket
+}
+
+func (b *RetryBudget) totals(now time.Time) (int, int) {
+	epoch := now.UnixNano() / int64(b.width)
+	requests, retries := 0, 0
+	for _, bucket := range b.buckets {
+		if epoch-bucket.epoch < retryBudgetBuckets {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	return requests, retries
+}
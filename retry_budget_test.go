@@ -0,0 +1,73 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudgetAllowsTheMinimumRetries(t *testing.T) {
+	budget := flow.NewRetryBudget(0, 2, time.Minute)
+	assert.True(t, budget.AllowRetry())
+	assert.True(t, budget.AllowRetry())
+	assert.False(t, budget.AllowRetry())
+}
+
+func TestRetryBudgetAllowsARatioOfRequests(t *testing.T) {
+	budget := flow.NewRetryBudget(0.1, 0, time.Minute)
+	for range 20 {
+		budget.RecordRequest()
+	}
+	assert.True(t, budget.AllowRetry())
+	assert.True(t, budget.AllowRetry())
+	assert.False(t, budget.AllowRetry())
+}
+
+func TestRetryBudgetRefillsAfterTheWindow(t *testing.T) {
+	budget := flow.NewRetryBudget(0, 1, time.Millisecond*10)
+	assert.True(t, budget.AllowRetry())
+	assert.False(t, budget.AllowRetry())
+
+	time.Sleep(time.Millisecond * 15)
+
+	assert.True(t, budget.AllowRetry())
+}
+
+func TestItStopsRetryingWhenTheBudgetIsExhausted(t *testing.T) {
+	bongo := errors.New("bongo")
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		calls++
+		return struct{}{}, bongo
+	}
+
+	budget := flow.NewRetryBudget(0, 1, time.Minute)
+	retry := flow.Retry(do, 5, flow.RetryWithBudget(budget))
+
+	_, err := retry(context.Background())
+	assert.ErrorIs(t, err, flow.ErrRetryBudgetExhausted)
+	assert.ErrorIs(t, err, bongo)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	_, err = retry(context.Background())
+	assert.ErrorIs(t, err, flow.ErrRetryBudgetExhausted)
+	assert.Equal(t, 1, calls)
+}
+
+func TestACancelledRetryDoesntTakeFromTheBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	do := func(ctx context.Context) (struct{}, error) {
+		cancel()
+		return struct{}{}, errors.New("bongo")
+	}
+
+	budget := flow.NewRetryBudget(0, 1, time.Minute)
+	_, err := flow.Retry(do, 5, flow.RetryWithBudget(budget))(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, budget.AllowRetry())
+}
@@ -0,0 +1,127 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// An http.RoundTripper that retries requests that fail, or that
+// receive a 429 or 503 response. The Retry-After header on those
+// responses is used as the delay before the next attempt instead of
+// the backoff.
+type RetryTransport struct {
+	// The transport used to make each attempt, http.DefaultTransport
+	// is used when this is nil
+	Next http.RoundTripper
+	// The maximum number of attempts for each request
+	Times int
+	// Calculates the delay between attempts
+	Backoff Backoff
+	// Options passed to the underlying retry
+	Options []RetryOption
+	// By default, only requests with idempotent methods are retried.
+	// Set this to retry every request that has a rewindable body.
+	AllowNonIdempotent bool
+}
+
+func NewRetryTransport(next http.RoundTripper, times int, backoff Backoff, opts ...RetryOption) *RetryTransport {
+	return &RetryTransport{
+		Next:    next,
+		Times:   times,
+		Backoff: backoff,
+		Options: opts,
+	}
+}
+
+func (t *RetryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if !t.AllowNonIdempotent && !idempotent(r) {
+		return next.RoundTrip(r)
+	}
+	if !rewindable(r) {
+		return next.RoundTrip(r)
+	}
+
+	backoff := t.Backoff
+	if backoff == nil {
+		backoff = ConstantBackoff(0)
+	}
+
+	attempts := 0
+	var prev *http.Response
+	resp, err := RetryBackoff(func(ctx context.Context) (*http.Response, error) {
+		if prev != nil {
+			discard(prev)
+			prev = nil
+		}
+		attempts++
+
+		resp, err := sendAttempt(ctx, r, attempts > 1, next.RoundTrip)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			prev = resp
+			return resp, &retryableResponse{
+				status: resp.StatusCode,
+				after:  parseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
+		return resp, nil
+	}, max(t.Times, 1), backoff, t.Options...)(r.Context())
+
+	if err != nil {
+		var rr *retryableResponse
+		if r.Context().Err() == nil && errors.As(err, &rr) && resp != nil {
+			// We ran out of attempts, so give the caller the last
+			// response like the underlying transport would
+			return resp, nil
+		}
+		if prev != nil {
+			discard(prev)
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+type retryableResponse struct {
+	status int
+	after  time.Duration
+}
+
+func (r *retryableResponse) Error() string {
+	return fmt.Sprintf("received retryable status %d", r.status)
+}
+
+func (r *retryableResponse) RetryAfter() time.Duration {
+	return r.after
+}
+
+// Parses the value of a Retry-After header, which can either be
+// a number of seconds or an http date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if until := time.Until(date); until > 0 {
+			return until
+		}
+	}
+	return 0
+}
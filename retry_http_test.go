@@ -0,0 +1,141 @@
+package flow_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransportRetriesTooManyRequests(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("bongo"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: flow.NewRetryTransport(nil, 3, flow.ConstantBackoff(0)),
+	}
+	resp, err := client.Get(srv.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetryTransportReturnsTheLastResponseWhenItRunsOutOfAttempts(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: flow.NewRetryTransport(nil, 2, flow.ConstantBackoff(0)),
+	}
+	resp, err := client.Get(srv.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryTransportWaitsForTheRetryAfterHeader(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: flow.NewRetryTransport(nil, 2, flow.ConstantBackoff(0)),
+	}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryTransportDoesntRetryNonIdempotentRequests(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: flow.NewRetryTransport(nil, 3, flow.ConstantBackoff(0)),
+	}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("bongo"))
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetryTransportRewindsTheBody(t *testing.T) {
+	calls := &atomic.Int32{}
+	bodies := make(chan string, 3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	transport := flow.NewRetryTransport(nil, 3, flow.ConstantBackoff(0))
+	transport.AllowNonIdempotent = true
+	client := &http.Client{Transport: transport}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("bongo"))
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	close(bodies)
+	for body := range bodies {
+		require.Equal(t, "bongo", body)
+	}
+}
+
+func TestRetryTransportRetriesAttemptsThatTimeOut(t *testing.T) {
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("bongo"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: flow.NewRetryTransport(nil, 2, flow.ConstantBackoff(0), flow.AttemptTimeout(time.Millisecond*50)),
+	}
+	resp, err := client.Get(srv.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.Nil(t, err)
+	require.Equal(t, "bongo", string(body))
+	require.Equal(t, int32(2), calls.Load())
+}
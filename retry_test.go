@@ -3,10 +3,13 @@ package flow_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/henrywhitaker3/flow"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestItRetriesTheFuncIfItErrors(t *testing.T) {
@@ -53,3 +56,249 @@ func TestItOnlyCallsOnceIfItPassesFirstTime(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, calls)
 }
+
+func TestItWaitsForTheBackoffBetweenAttempts(t *testing.T) {
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		defer func() { calls++ }()
+		if calls < 2 {
+			return struct{}{}, errors.New("bongo")
+		}
+		return struct{}{}, nil
+	}
+
+	start := time.Now()
+	retry := flow.RetryBackoff(do, 3, flow.LinearBackoff(time.Millisecond, time.Millisecond))
+	_, err := retry(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*3)
+}
+
+func TestItDoesntWaitAfterTheLastAttempt(t *testing.T) {
+	do := func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, errors.New("bongo")
+	}
+
+	start := time.Now()
+	retry := flow.RetryDelay(do, 1, time.Second)
+	_, err := retry(context.Background())
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestItStopsWaitingWhenTheContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	bongo := errors.New("bongo")
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		calls++
+		return struct{}{}, bongo
+	}
+
+	start := time.Now()
+	retry := flow.RetryDelay(do, 3, time.Second)
+	_, err := retry(ctx)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, bongo)
+	assert.Equal(t, 1, calls)
+}
+
+func TestItDoesntCallTheFuncIfTheContextIsAlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		calls++
+		return struct{}{}, nil
+	}
+
+	_, err := flow.Retry(do, 3)(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, calls)
+}
+
+func TestItDoesntRetryPermanentErrors(t *testing.T) {
+	bongo := errors.New("bongo")
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		calls++
+		return struct{}{}, fmt.Errorf("wrapped: %w", flow.Permanent(bongo))
+	}
+
+	_, err := flow.Retry(do, 3)(context.Background())
+	assert.ErrorIs(t, err, bongo)
+	assert.Equal(t, 1, calls)
+}
+
+func TestItOnlyRetriesErrorsMatchingThePredicate(t *testing.T) {
+	bongo := errors.New("bongo")
+	bingo := errors.New("bingo")
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		calls++
+		if calls == 1 {
+			return struct{}{}, bongo
+		}
+		return struct{}{}, bingo
+	}
+
+	retry := flow.Retry(do, 5, flow.RetryIf(func(err error) bool {
+		return errors.Is(err, bongo)
+	}))
+	_, err := retry(context.Background())
+	assert.ErrorIs(t, err, bingo)
+	assert.Equal(t, 2, calls)
+}
+
+func TestItReturnsTheErrorsFromEveryAttempt(t *testing.T) {
+	errs := []error{errors.New("bongo"), errors.New("bingo"), errors.New("bango")}
+	calls := 0
+	do := func(ctx context.Context) (struct{}, error) {
+		defer func() { calls++ }()
+		return struct{}{}, errs[calls]
+	}
+
+	_, err := flow.Retry(do, 3)(context.Background())
+
+	var rerr *flow.RetryError
+	require.ErrorAs(t, err, &rerr)
+	require.Len(t, rerr.Attempts, 3)
+	for i, attempt := range rerr.Attempts {
+		assert.Equal(t, errs[i], attempt.Err)
+		assert.False(t, attempt.Start.IsZero())
+		assert.ErrorIs(t, err, errs[i])
+	}
+	assert.Equal(t, errs[2], rerr.Last())
+	assert.Equal(t, "bango", err.Error())
+}
+
+func TestARetryErrorWithNoAttemptsDoesntPanic(t *testing.T) {
+	rerr := &flow.RetryError{}
+	assert.Equal(t, "retry made no attempts", rerr.Error())
+	assert.Nil(t, rerr.Last())
+	assert.Empty(t, rerr.Unwrap())
+}
+
+func TestItCallsTheRetryHooks(t *testing.T) {
+	bongo := errors.New("bongo")
+	do := func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, bongo
+	}
+
+	retries := []int{}
+	delays := []time.Duration{}
+	gaveUp := 0
+	retry := flow.RetryBackoff(
+		do,
+		3,
+		flow.LinearBackoff(0, time.Millisecond),
+		flow.OnRetry(func(attempt int, err error, next time.Duration) {
+			assert.ErrorIs(t, err, bongo)
+			retries = append(retries, attempt)
+			delays = append(delays, next)
+		}),
+		flow.OnGiveUp(func(attempts int, err error) {
+			assert.ErrorIs(t, err, bongo)
+			gaveUp = attempts
+		}),
+	)
+	_, err := retry(context.Background())
+	assert.ErrorIs(t, err, bongo)
+	assert.Equal(t, []int{1, 2}, retries)
+	assert.Equal(t, []time.Duration{0, time.Millisecond}, delays)
+	assert.Equal(t, 3, gaveUp)
+}
+
+func TestItDoesntCallTheRetryHookWhenTheContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	do := func(ctx context.Context) (struct{}, error) {
+		cancel()
+		return struct{}{}, errors.New("bongo")
+	}
+
+	retried := false
+	gaveUp := 0
+	retry := flow.Retry(do, 3,
+		flow.OnRetry(func(int, error, time.Duration) {
+			retried = true
+		}),
+		flow.OnGiveUp(func(attempts int, err error) {
+			assert.ErrorIs(t, err, context.Canceled)
+			gaveUp = attempts
+		}),
+	)
+	_, err := retry(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, retried)
+	assert.Equal(t, 1, gaveUp)
+}
+
+func TestItDoesntCallTheGiveUpHookWhenItSucceeds(t *testing.T) {
+	do := func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, nil
+	}
+
+	called := false
+	retry := flow.Retry(do, 3, flow.OnGiveUp(func(int, error) {
+		called = true
+	}))
+	_, err := retry(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, called)
+}
+
+func TestItRetriesAttemptsThatTimeOut(t *testing.T) {
+	calls := 0
+	do := func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 5, nil
+	}
+
+	retry := flow.Retry(do, 2, flow.AttemptTimeout(time.Millisecond), flow.RetryIf(func(err error) bool {
+		return false
+	}))
+	out, err := retry(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 5, out)
+	assert.Equal(t, 2, calls)
+}
+
+func TestItDoesntRetryPermanentErrorsFromAttemptsThatTimeOut(t *testing.T) {
+	calls := 0
+	do := func(ctx context.Context) (int, error) {
+		calls++
+		<-ctx.Done()
+		return 0, flow.Permanent(ctx.Err())
+	}
+
+	_, err := flow.Retry(do, 3, flow.AttemptTimeout(time.Millisecond))(context.Background())
+	assert.ErrorIs(t, err, flow.ErrAttemptTimeout)
+	assert.Equal(t, 1, calls)
+}
+
+func TestItStopsWhenTheOverallContextTimesOut(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*5)
+	defer cancel()
+
+	calls := 0
+	do := func(ctx context.Context) (int, error) {
+		calls++
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	retry := flow.Retry(do, 100, flow.AttemptTimeout(time.Second))
+	_, err := retry(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, flow.ErrAttemptTimeout)
+	assert.Equal(t, 1, calls)
+}
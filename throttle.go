@@ -3,6 +3,7 @@ package flow
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -11,37 +12,82 @@ var (
 	ErrThrottled = errors.New("throttled")
 )
 
-func ThrottleIn[T any](f EffectorIn[T], every time.Duration) EffectorIn[T] {
-	called := false
-	once := &sync.Once{}
+// Returned when a call is throttled, matches ErrThrottled when using
+// errors.Is. Retries wait for RetryAfter before the next attempt.
+type ThrottledError struct {
+	after time.Duration
+}
 
-	return func(ctx context.Context, t T) error {
-		once.Do(func() {
-			ticker := time.NewTicker(every)
-			go func() {
-				defer ticker.Stop()
-				for {
-					select {
-					case <-ctx.Done():
-						return
-					case <-ticker.C:
-						called = false
-					}
-				}
-			}()
-		})
-
-		if !called {
-			called = true
-			return f(ctx, t)
+func throttled(after time.Duration) error {
+	return &ThrottledError{after: after}
+}
+
+func (t *ThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrThrottled, t.after)
+}
+
+func (t *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// How long until the next call can be made
+func (t *ThrottledError) RetryAfter() time.Duration {
+	return t.after
+}
+
+type throttleOptions struct {
+	trailing bool
+	wait     bool
+}
+
+type ThrottleOption func(*throttleOptions)
+
+// When calls are throttled, fire the latest one once the duration has
+// passed. The throttled calls still return ErrThrottled straight away.
+func ThrottleTrailing() ThrottleOption {
+	return func(o *throttleOptions) {
+		o.trailing = true
+	}
+}
+
+// The same as ThrottleTrailing, but throttled calls wait for the
+// latest one to fire and return its result. If the context is
+// cancelled while waiting, the context error is returned.
+func ThrottleTrailingWait() ThrottleOption {
+	return func(o *throttleOptions) {
+		o.trailing = true
+		o.wait = true
+	}
+}
+
+// Return an EffectorIn that, when called, will only fire once every duration
+func ThrottleIn[T any](f EffectorIn[T], every time.Duration, opts ...ThrottleOption) EffectorIn[T] {
+	o := throttleOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	th := newThrottler(every)
+	if o.trailing {
+		tr := newTrailer[struct{}](th, o.wait)
+		return func(ctx context.Context, t T) error {
+			_, err := tr.do(ctx, func(ctx context.Context) (struct{}, error) {
+				return struct{}{}, f(ctx, t)
+			})
+			return err
 		}
+	}
 
-		return ErrThrottled
+	return func(ctx context.Context, t T) error {
+		if ok, after := th.allow(); !ok {
+			return throttled(after)
+		}
+		return f(ctx, t)
 	}
 }
 
-func SilentThrottleIn[T any](f EffectorIn[T], every time.Duration) EffectorIn[T] {
-	tf := ThrottleIn[T](f, every)
+func SilentThrottleIn[T any](f EffectorIn[T], every time.Duration, opts ...ThrottleOption) EffectorIn[T] {
+	tf := ThrottleIn[T](f, every, opts...)
 	return func(ctx context.Context, t T) error {
 		err := tf(ctx, t)
 		if errors.Is(err, ErrThrottled) {
@@ -52,45 +98,40 @@ func SilentThrottleIn[T any](f EffectorIn[T], every time.Duration) EffectorIn[T]
 }
 
 // Return an Effector that, when called, will only fire once every duration
-func Throttle[T any](f Effector[T], every time.Duration) Effector[T] {
-	called := false
-	once := sync.Once{}
+func Throttle[T any](f Effector[T], every time.Duration, opts ...ThrottleOption) Effector[T] {
+	o := throttleOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	return func(ctx context.Context) (T, error) {
-		// Run a loop here to turn called false after every x duration
-		once.Do(func() {
-			ticker := time.NewTicker(every)
-			go func() {
-				defer ticker.Stop()
-				for {
-					select {
-					case <-ctx.Done():
-						return
-
-					case <-ticker.C:
-						called = false
-					}
-				}
-			}()
-		})
-
-		if !called {
-			called = true
-			return f(ctx)
+	th := newThrottler(every)
+	if o.trailing {
+		tr := newTrailer[T](th, o.wait)
+		return func(ctx context.Context) (T, error) {
+			return tr.do(ctx, f)
 		}
+	}
 
-		var out T
-		return out, ErrThrottled
+	return func(ctx context.Context) (T, error) {
+		if ok, after := th.allow(); !ok {
+			var out T
+			return out, throttled(after)
+		}
+		return f(ctx)
 	}
 }
 
-func SilentThrottle[T any](f Effector[T], every time.Duration) Effector[T] {
+func SilentThrottle[T any](f Effector[T], every time.Duration, opts ...ThrottleOption) Effector[T] {
+	mu := &sync.Mutex{}
 	var value T
 	var err error
 
-	tf := Throttle(f, every)
+	tf := Throttle(f, every, opts...)
 	return func(ctx context.Context) (T, error) {
 		res, iErr := tf(ctx)
+
+		mu.Lock()
+		defer mu.Unlock()
 		if errors.Is(iErr, ErrThrottled) {
 			return value, nil
 		}
@@ -101,3 +142,185 @@ func SilentThrottle[T any](f Effector[T], every time.Duration) Effector[T] {
 		return value, err
 	}
 }
+
+// Tracks when a throttled function last fired
+type throttler struct {
+	mu    *sync.Mutex
+	every time.Duration
+	last  time.Time
+}
+
+func newThrottler(every time.Duration) *throttler {
+	return &throttler{
+		mu:    &sync.Mutex{},
+		every: every,
+	}
+}
+
+// Reports whether the function can fire now, and records the call
+// if it can. If it can't, it returns how long until it can.
+func (t *throttler) allow() (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if !t.last.IsZero() && now.Sub(t.last) < t.every {
+		return false, t.every - now.Sub(t.last)
+	}
+	t.last = now
+	return true, 0
+}
+
+// Records a call that fired now, starting a new window
+func (t *throttler) fired() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last = time.Now()
+}
+
+// How long until the function can fire again
+func (t *throttler) remaining() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.last.IsZero() {
+		return 0
+	}
+	return max(t.every-time.Since(t.last), 0)
+}
+
+type throttleResult[T any] struct {
+	out T
+	err error
+}
+
+// Holds on to the latest throttled call and fires it once the
+// throttle window has passed
+type trailer[T any] struct {
+	mu   *sync.Mutex
+	th   *throttler
+	wait bool
+
+	// The latest throttled call, nil when nothing is waiting to fire
+	pending func() (T, error)
+	// Callers waiting for the pending call to fire
+	waiters []chan throttleResult[T]
+}
+
+func newTrailer[T any](th *throttler, wait bool) *trailer[T] {
+	return &trailer[T]{
+		mu:   &sync.Mutex{},
+		th:   th,
+		wait: wait,
+	}
+}
+
+// Fires the call now if the throttle allows it, otherwise makes it
+// the pending call
+func (tr *trailer[T]) do(ctx context.Context, call Effector[T]) (T, error) {
+	tr.mu.Lock()
+	if tr.pending == nil {
+		if ok, _ := tr.th.allow(); ok {
+			tr.mu.Unlock()
+			return call(ctx)
+		}
+		time.AfterFunc(tr.th.remaining(), tr.fire)
+	}
+	// The pending call fires after this caller has returned, so it
+	// shouldn't be cancelled along with them
+	tr.pending = func() (T, error) {
+		return call(context.WithoutCancel(ctx))
+	}
+	var res chan throttleResult[T]
+	if tr.wait {
+		res = make(chan throttleResult[T], 1)
+		tr.waiters = append(tr.waiters, res)
+	}
+	after := tr.th.remaining()
+	tr.mu.Unlock()
+
+	var out T
+	if res == nil {
+		return out, throttled(after)
+	}
+	select {
+	case <-ctx.Done():
+		return out, ctx.Err()
+	case r := <-res:
+		return r.out, r.err
+	}
+}
+
+func (tr *trailer[T]) fire() {
+	tr.mu.Lock()
+	call, waiters := tr.pending, tr.waiters
+	tr.pending, tr.waiters = nil, nil
+	tr.th.fired()
+	tr.mu.Unlock()
+
+	out, err := call()
+	for _, w := range waiters {
+		w <- throttleResult[T]{out: out, err: err}
+	}
+}
+
+// Return an Effector that only fires once every duration, calls made
+// before the duration has passed wait for their turn in the order they
+// were made instead of returning ErrThrottled. If the context is
+// cancelled while waiting, the context error is returned.
+func ThrottleWait[T any](f Effector[T], every time.Duration) Effector[T] {
+	q := newThrottleQueue(every)
+	return func(ctx context.Context) (T, error) {
+		if err := q.wait(ctx); err != nil {
+			var out T
+			return out, err
+		}
+		return f(ctx)
+	}
+}
+
+// Does the same as ThrottleWait for an EffectorIn
+func ThrottleWaitIn[T any](f EffectorIn[T], every time.Duration) EffectorIn[T] {
+	q := newThrottleQueue(every)
+	return func(ctx context.Context, t T) error {
+		if err := q.wait(ctx); err != nil {
+			return err
+		}
+		return f(ctx, t)
+	}
+}
+
+type throttleQueue struct {
+	every time.Duration
+	// Holding a slot in the channel means it's your turn, waiting
+	// senders are woken up in the order they started waiting
+	turn chan struct{}
+	// When the last call was let through, only accessed while
+	// holding the turn
+	last time.Time
+}
+
+func newThrottleQueue(every time.Duration) *throttleQueue {
+	return &throttleQueue{
+		every: every,
+		turn:  make(chan struct{}, 1),
+	}
+}
+
+// Blocks until it's the callers turn and the duration has passed
+// since the last call
+func (q *throttleQueue) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case q.turn <- struct{}{}:
+	}
+	defer func() { <-q.turn }()
+
+	if !q.last.IsZero() {
+		if err := wait(ctx, time.Until(q.last.Add(q.every))); err != nil {
+			return err
+		}
+	}
+	q.last = time.Now()
+	return nil
+}
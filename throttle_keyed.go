@@ -0,0 +1,90 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Return an EffectorIn that throttles calls separately for each key
+// returned by the key func, so each key will only fire once every
+// duration. Keys that haven't been called for longer than the
+// duration are forgotten. It is safe for concurrent use.
+func ThrottleBy[T any, K comparable](f EffectorIn[T], every time.Duration, key func(T) K) EffectorIn[T] {
+	th := newKeyedThrottler[K](every)
+	return func(ctx context.Context, t T) error {
+		if ok, after := th.allow(key(t)); !ok {
+			return throttled(after)
+		}
+		return f(ctx, t)
+	}
+}
+
+// Does the same as ThrottleBy, but returns nil instead of
+// ErrThrottled when a call is throttled
+func SilentThrottleBy[T any, K comparable](f EffectorIn[T], every time.Duration, key func(T) K) EffectorIn[T] {
+	tf := ThrottleBy(f, every, key)
+	return func(ctx context.Context, t T) error {
+		err := tf(ctx, t)
+		if errors.Is(err, ErrThrottled) {
+			return nil
+		}
+		return err
+	}
+}
+
+// Tracks when a throttled function last fired for each key
+type keyedThrottler[K comparable] struct {
+	mu    *sync.Mutex
+	every time.Duration
+	last  map[K]time.Time
+	// When idle keys were last evicted
+	evicted time.Time
+}
+
+func newKeyedThrottler[K comparable](every time.Duration) *keyedThrottler[K] {
+	return &keyedThrottler[K]{
+		mu:      &sync.Mutex{},
+		every:   every,
+		last:    map[K]time.Time{},
+		evicted: time.Now(),
+	}
+}
+
+// Reports whether the function can fire now for the key, and records
+// the call if it can. If it can't, it returns how long until it can.
+func (k *keyedThrottler[K]) allow(key K) (bool, time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	k.evict(now)
+
+	if last, ok := k.last[key]; ok && now.Sub(last) < k.every {
+		return false, k.every - now.Sub(last)
+	}
+	k.last[key] = now
+	return true, 0
+}
+
+// Removes keys whose window has passed, at most once per window so
+// the cost is spread out over the calls
+func (k *keyedThrottler[K]) evict(now time.Time) {
+	if now.Sub(k.evicted) < k.every {
+		return
+	}
+	for key, last := range k.last {
+		if now.Sub(last) >= k.every {
+			delete(k.last, key)
+		}
+	}
+	k.evicted = now
+}
+
+// The number of keys currently being tracked
+func (k *keyedThrottler[K]) len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.last)
+}
@@ -0,0 +1,71 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type keyedCall struct {
+	user string
+	val  int
+}
+
+func TestThrottleByThrottlesEachKeySeparately(t *testing.T) {
+	mu := &sync.Mutex{}
+	set := map[string]int{}
+	do := func(ctx context.Context, in keyedCall) error {
+		mu.Lock()
+		defer mu.Unlock()
+		set[in.user] += in.val
+		return nil
+	}
+
+	run := ThrottleBy(do, time.Millisecond*5, func(in keyedCall) string {
+		return in.user
+	})
+
+	require.Nil(t, run(context.Background(), keyedCall{user: "bongo", val: 1}))
+	require.Nil(t, run(context.Background(), keyedCall{user: "bingo", val: 1}))
+	require.ErrorIs(t, run(context.Background(), keyedCall{user: "bongo", val: 1}), ErrThrottled)
+	require.Equal(t, map[string]int{"bongo": 1, "bingo": 1}, set)
+
+	time.Sleep(time.Millisecond * 6)
+
+	require.Nil(t, run(context.Background(), keyedCall{user: "bongo", val: 1}))
+	require.Equal(t, map[string]int{"bongo": 2, "bingo": 1}, set)
+}
+
+func TestSilentThrottleByDoesntReturnThrottledErrors(t *testing.T) {
+	calls := 0
+	do := func(ctx context.Context, in keyedCall) error {
+		calls++
+		return nil
+	}
+
+	run := SilentThrottleBy(do, time.Hour, func(in keyedCall) string {
+		return in.user
+	})
+
+	require.Nil(t, run(context.Background(), keyedCall{user: "bongo"}))
+	require.Nil(t, run(context.Background(), keyedCall{user: "bongo"}))
+	require.Equal(t, 1, calls)
+}
+
+func TestKeyedThrottlerEvictsIdleKeys(t *testing.T) {
+	th := newKeyedThrottler[string](time.Millisecond * 5)
+	ok, _ := th.allow("bongo")
+	require.True(t, ok)
+	ok, _ = th.allow("bingo")
+	require.True(t, ok)
+	require.Equal(t, 2, th.len())
+
+	time.Sleep(time.Millisecond * 6)
+
+	ok, _ = th.allow("bango")
+	require.True(t, ok)
+	require.Equal(t, 1, th.len())
+}
@@ -2,6 +2,8 @@ package flow_test
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -166,3 +168,166 @@ func TestInItSilentlyReturnsTheStartValueWhenCalledSilently(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, set)
 }
+
+func TestThrottleWaitWaitsForTheDurationToPass(t *testing.T) {
+	i := 0
+	do := func(ctx context.Context) (int, error) {
+		i++
+		return i, nil
+	}
+
+	run := flow.ThrottleWait(do, time.Millisecond*5)
+	start := time.Now()
+	for x := range 3 {
+		out, err := run(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, x+1, out)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*10)
+}
+
+func TestThrottleWaitReturnsWhenTheContextIsCancelled(t *testing.T) {
+	do := func(ctx context.Context) (int, error) {
+		return 1, nil
+	}
+
+	run := flow.ThrottleWait(do, time.Hour)
+	_, err := run(context.Background())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	out, err := run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, out)
+}
+
+func TestThrottleWaitInRunsCallsInOrder(t *testing.T) {
+	mu := &sync.Mutex{}
+	order := []int{}
+	do := func(ctx context.Context, in int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, in)
+		return nil
+	}
+
+	run := flow.ThrottleWaitIn(do, time.Millisecond*2)
+	wg := &sync.WaitGroup{}
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, run(context.Background(), i))
+		}()
+		// Give each goroutine time to join the queue
+		time.Sleep(time.Millisecond / 2)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+func TestItKeepsThrottlingAfterTheFirstContextIsCancelled(t *testing.T) {
+	do := func(ctx context.Context) (int, error) {
+		return 1, nil
+	}
+
+	run := flow.Throttle(do, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := run(ctx)
+	assert.Nil(t, err)
+	cancel()
+
+	time.Sleep(time.Millisecond * 2)
+
+	out, err := run(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, out)
+}
+
+func TestItOnlyFiresOnceWhenCalledConcurrently(t *testing.T) {
+	calls := &atomic.Int32{}
+	do := func(ctx context.Context, in int) error {
+		calls.Add(1)
+		return nil
+	}
+
+	run := flow.SilentThrottleIn(do, time.Hour)
+	wg := &sync.WaitGroup{}
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, run(context.Background(), i))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestItFiresTheLatestThrottledCallWhenTrailing(t *testing.T) {
+	mu := &sync.Mutex{}
+	set := []int{}
+	do := func(ctx context.Context, in int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		set = append(set, in)
+		return nil
+	}
+
+	run := flow.ThrottleIn(do, time.Millisecond*50, flow.ThrottleTrailing())
+	assert.Nil(t, run(context.Background(), 1))
+	assert.ErrorIs(t, run(context.Background(), 2), flow.ErrThrottled)
+	assert.ErrorIs(t, run(context.Background(), 3), flow.ErrThrottled)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(set) == 2
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{1, 3}, set)
+}
+
+func TestItReturnsTheTrailingResultToWaitingCallers(t *testing.T) {
+	calls := &atomic.Int32{}
+	do := func(ctx context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	}
+
+	run := flow.Throttle(do, time.Millisecond*5, flow.ThrottleTrailingWait())
+	out, err := run(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, out)
+
+	wg := &sync.WaitGroup{}
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := run(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 2, out)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestItStopsWaitingForTheTrailingCallWhenTheContextIsCancelled(t *testing.T) {
+	do := func(ctx context.Context) (int, error) {
+		return 1, nil
+	}
+
+	run := flow.Throttle(do, time.Hour, flow.ThrottleTrailingWait())
+	_, err := run(context.Background())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,170 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How calls are counted when throttling to a number of calls per
+// duration
+type WindowAlgorithm int
+
+const (
+	// Counts calls in consecutive windows of the duration, starting
+	// from the first call. Cheap, but allows up to twice the limit
+	// around the edge of a window.
+	FixedWindow WindowAlgorithm = iota
+	// Remembers the time of every call in the last duration. Exact,
+	// but stores up to n timestamps.
+	SlidingLog
+	// Estimates the calls in the last duration by weighting the count
+	// from the previous fixed window by how much of it overlaps. Cheap
+	// and smooth, but the RetryAfter is an estimate.
+	SlidingCounter
+)
+
+// Return an Effector that will only fire n times every duration,
+// throttled calls return a *ThrottledError
+func ThrottleN[T any](f Effector[T], n int, every time.Duration, algo WindowAlgorithm) Effector[T] {
+	w := newWindow(n, every, algo)
+	return func(ctx context.Context) (T, error) {
+		if ok, after := w.allow(); !ok {
+			var out T
+			return out, throttled(after)
+		}
+		return f(ctx)
+	}
+}
+
+// Does the same as ThrottleN for an EffectorIn
+func ThrottleNIn[T any](f EffectorIn[T], n int, every time.Duration, algo WindowAlgorithm) EffectorIn[T] {
+	w := newWindow(n, every, algo)
+	return func(ctx context.Context, t T) error {
+		if ok, after := w.allow(); !ok {
+			return throttled(after)
+		}
+		return f(ctx, t)
+	}
+}
+
+type window interface {
+	// Reports whether a call can be made now, and records it if it can.
+	// If it can't, it returns how long until one can be.
+	allow() (bool, time.Duration)
+}
+
+func newWindow(n int, every time.Duration, algo WindowAlgorithm) window {
+	n = max(n, 1)
+	switch algo {
+	case SlidingLog:
+		return &slidingLog{mu: &sync.Mutex{}, n: n, every: every}
+	case SlidingCounter:
+		return &slidingCounter{mu: &sync.Mutex{}, n: n, every: every}
+	default:
+		return &fixedWindow{mu: &sync.Mutex{}, n: n, every: every}
+	}
+}
+
+type fixedWindow struct {
+	mu    *sync.Mutex
+	n     int
+	every time.Duration
+
+	start time.Time
+	count int
+}
+
+func (w *fixedWindow) allow() (bool, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if w.start.IsZero() || now.Sub(w.start) >= w.every {
+		w.start = now
+		w.count = 0
+	}
+	if w.count >= w.n {
+		return false, w.every - now.Sub(w.start)
+	}
+	w.count++
+	return true, 0
+}
+
+type slidingLog struct {
+	mu    *sync.Mutex
+	n     int
+	every time.Duration
+
+	// The times of the calls in the last duration, oldest first
+	calls []time.Time
+}
+
+func (w *slidingLog) allow() (bool, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	expired := 0
+	for expired < len(w.calls) && now.Sub(w.calls[expired]) >= w.every {
+		expired++
+	}
+	w.calls = w.calls[expired:]
+
+	if len(w.calls) >= w.n {
+		return false, w.every - now.Sub(w.calls[0])
+	}
+	w.calls = append(w.calls, now)
+	return true, 0
+}
+
+type slidingCounter struct {
+	mu    *sync.Mutex
+	n     int
+	every time.Duration
+
+	start time.Time
+	prev  int
+	count int
+}
+
+func (w *slidingCounter) allow() (bool, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.every <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	if w.start.IsZero() {
+		w.start = now
+	}
+	if elapsed := now.Sub(w.start); elapsed >= w.every {
+		windows := elapsed / w.every
+		if windows == 1 {
+			w.prev = w.count
+		} else {
+			w.prev = 0
+		}
+		w.count = 0
+		w.start = w.start.Add(windows * w.every)
+	}
+
+	elapsed := now.Sub(w.start)
+	weight := 1 - float64(elapsed)/float64(w.every)
+	if float64(w.prev)*weight+float64(w.count) < float64(w.n) {
+		w.count++
+		return true, 0
+	}
+
+	remaining := w.every - elapsed
+	if w.count >= w.n || w.prev == 0 {
+		return false, remaining
+	}
+	// Wait until enough of the previous window has slid out to fit
+	// another call in
+	free := float64(w.n-w.count) / float64(w.prev)
+	after := time.Duration((1-free)*float64(w.every)) - elapsed
+	return false, min(max(after, 0), remaining)
+}
@@ -0,0 +1,122 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henrywhitaker3/flow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var windowAlgorithms = map[string]flow.WindowAlgorithm{
+	"fixed window":    flow.FixedWindow,
+	"sliding log":     flow.SlidingLog,
+	"sliding counter": flow.SlidingCounter,
+}
+
+func TestThrottleNAllowsNCallsPerDuration(t *testing.T) {
+	for name, algo := range windowAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			do := func(ctx context.Context) (int, error) {
+				calls++
+				return calls, nil
+			}
+
+			run := flow.ThrottleN(do, 3, time.Millisecond*20, algo)
+			for i := range 3 {
+				out, err := run(context.Background())
+				assert.Nil(t, err)
+				assert.Equal(t, i+1, out)
+			}
+
+			out, err := run(context.Background())
+			assert.ErrorIs(t, err, flow.ErrThrottled)
+			assert.Empty(t, out)
+
+			var terr *flow.ThrottledError
+			require.True(t, errors.As(err, &terr))
+			assert.Greater(t, terr.RetryAfter(), time.Duration(0))
+			assert.LessOrEqual(t, terr.RetryAfter(), time.Millisecond*20)
+
+			time.Sleep(time.Millisecond * 45)
+
+			_, err = run(context.Background())
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestThrottleNInAllowsNCallsPerDuration(t *testing.T) {
+	for name, algo := range windowAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			set := 0
+			do := func(ctx context.Context, in int) error {
+				set += in
+				return nil
+			}
+
+			run := flow.ThrottleNIn(do, 2, time.Hour, algo)
+			assert.Nil(t, run(context.Background(), 1))
+			assert.Nil(t, run(context.Background(), 1))
+			assert.ErrorIs(t, run(context.Background(), 1), flow.ErrThrottled)
+			assert.Equal(t, 2, set)
+		})
+	}
+}
+
+func TestThrottleNAllowsEveryCallWithoutADuration(t *testing.T) {
+	for name, algo := range windowAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			run := flow.ThrottleN(func(ctx context.Context) (int, error) {
+				return 1, nil
+			}, 2, 0, algo)
+			for range 5 {
+				_, err := run(context.Background())
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestSlidingLogFreesSlotsAsCallsExpire(t *testing.T) {
+	do := func(ctx context.Context) (int, error) {
+		return 1, nil
+	}
+
+	run := flow.ThrottleN(do, 2, time.Millisecond*20, flow.SlidingLog)
+	_, err := run(context.Background())
+	assert.Nil(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, err = run(context.Background())
+	assert.Nil(t, err)
+
+	_, err = run(context.Background())
+	var terr *flow.ThrottledError
+	require.ErrorAs(t, err, &terr)
+	assert.LessOrEqual(t, terr.RetryAfter(), time.Millisecond*10)
+
+	time.Sleep(terr.RetryAfter() + time.Millisecond)
+
+	_, err = run(context.Background())
+	assert.Nil(t, err)
+}
+
+func TestRetryWaitsForTheThrottle(t *testing.T) {
+	do := func(ctx context.Context) (int, error) {
+		return 1, nil
+	}
+
+	run := flow.Retry(flow.ThrottleN(do, 1, time.Millisecond*5, flow.FixedWindow), 2)
+	_, err := run(context.Background())
+	assert.Nil(t, err)
+
+	start := time.Now()
+	out, err := run(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, out)
+	assert.Greater(t, time.Since(start), time.Millisecond*3)
+}